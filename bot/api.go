@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+// apiPrefix is URL path prefix of REST API.
+const apiPrefix = "/api/"
+
+// APITranslateRequest is http POST:/api/v1/translate request.
+type APITranslateRequest struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Text []string `json:"text"`
+}
+
+// APITranslateResponse is http POST:/api/v1/translate response,
+// translations have the same order as request's texts.
+type APITranslateResponse struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Text []string `json:"text"`
+}
+
+// APILookupResponse is http GET:/api/v1/lookup response.
+type APILookupResponse struct {
+	Direction string                         `json:"dir"`
+	Word      string                         `json:"word"`
+	Def       []translator.JSONTrDictArticle `json:"def"`
+}
+
+// APIError is REST API error response.
+type APIError struct {
+	Error APIErrorInfo `json:"error"`
+}
+
+// APIErrorInfo is REST API error details.
+type APIErrorInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// isAPIRequest returns true if r is REST API request.
+func isAPIRequest(r *http.Request) bool {
+	return r != nil && strings.HasPrefix(r.URL.Path, apiPrefix)
+}
+
+// writeAPIError writes REST API error response.
+func writeAPIError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(&APIError{Error: APIErrorInfo{Code: code, Message: msg}})
+}
+
+// badRequest returns REST API error with http.StatusBadRequest code.
+func badRequest(format string, a ...interface{}) error {
+	return &httpError{code: http.StatusBadRequest, msg: fmt.Sprintf(format, a...)}
+}
+
+// handlerAPITranslate is handler for POST:/api/v1/translate request.
+func (s *Service) handlerAPITranslate(w http.ResponseWriter, r *http.Request) error {
+	req := &APITranslateRequest{}
+	if err := decodeRequest(r, req); err != nil {
+		return err
+	}
+	switch {
+	case req.From == "":
+		return badRequest("\"from\" language is required")
+	case req.To == "":
+		return badRequest("\"to\" language is required")
+	case len(req.Text) == 0:
+		return badRequest("\"text\" is required")
+	}
+	texts := make([]string, len(req.Text))
+	for i, text := range req.Text {
+		text, err := cleanText(text)
+		if err != nil {
+			return err
+		}
+		texts[i] = text
+	}
+	result, err := s.TranslateBatch(r.Context(), req.From+"-"+req.To, texts)
+	if err != nil {
+		s.loggerError.Printf("API translation error: %v", err)
+		return err
+	}
+	s.writeJSON(w, http.StatusOK, &APITranslateResponse{From: req.From, To: req.To, Text: result})
+	return nil
+}
+
+// handlerAPILookup is handler for GET:/api/v1/lookup request.
+func (s *Service) handlerAPILookup(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	direction, word := query.Get("dir"), strings.TrimSpace(query.Get("word"))
+	switch {
+	case direction == "":
+		return badRequest("\"dir\" parameter is required")
+	case word == "":
+		return badRequest("\"word\" parameter is required")
+	}
+	word, err := cleanText(word)
+	if err != nil {
+		return err
+	}
+	result, err := s.Lookup(r.Context(), direction, word)
+	if err != nil {
+		s.loggerError.Printf("API lookup error: %v", err)
+		return err
+	}
+	if len(result.Def) == 0 {
+		return &httpError{code: http.StatusNotFound, msg: fmt.Sprintf("word %q is not found", word)}
+	}
+	s.writeJSON(w, http.StatusOK, &APILookupResponse{Direction: direction, Word: word, Def: result.Def})
+	return nil
+}
+
+// handlerAPILanguages is handler for GET:/api/v1/languages request.
+func (s *Service) handlerAPILanguages(w http.ResponseWriter, r *http.Request) error {
+	return s.writeLanguages(w, r, s.Languages())
+}
+
+// handlerAPINotFound replies JSON error for unknown REST API routes.
+func (s *Service) handlerAPINotFound(w http.ResponseWriter, r *http.Request) error {
+	return &httpError{code: http.StatusNotFound, msg: fmt.Sprintf("unknown API method %v %v", r.Method, r.URL.Path)}
+}
+
+// methodNotAllowed returns handler which rejects requests to a route with other method.
+func methodNotAllowed(method string) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Allow", method)
+		return &httpError{code: http.StatusMethodNotAllowed, msg: fmt.Sprintf("method %v is not allowed, use %v", r.Method, method)}
+	}
+}
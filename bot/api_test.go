@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPI(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	testValues := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+		want   string
+	}{
+		{"POST", "/api/v1/translate", `{"from": "en", "to": "ru", "text": ["one", "two"]}`,
+			http.StatusOK, `{"from":"en","to":"ru","text":["[one]","[two]"]}`},
+		{"POST", "/api/v1/translate", `{"from": "en", "text": ["one"]}`, http.StatusBadRequest, ""},
+		{"POST", "/api/v1/translate", `{"from": "zz", "to": "ru", "text": ["one"]}`, http.StatusBadRequest, ""},
+		{"POST", "/api/v1/translate", `{"from": "en", "to": "ru", "text": []}`, http.StatusBadRequest, ""},
+		{"POST", "/api/v1/translate", `{"from": `, http.StatusBadRequest, ""},
+		{"GET", "/api/v1/translate", "", http.StatusMethodNotAllowed, ""},
+		{"GET", "/api/v1/lookup?dir=en-ru&word=time", "", http.StatusOK, ""},
+		{"GET", "/api/v1/lookup?dir=en-ru", "", http.StatusBadRequest, ""},
+		{"GET", "/api/v1/lookup?dir=de-ru&word=time", "", http.StatusBadRequest, ""},
+		{"GET", "/api/v1/languages", "", http.StatusOK, ""},
+		{"GET", "/api/v2/unknown", "", http.StatusNotFound, ""},
+	}
+	for i, v := range testValues {
+		req, err := http.NewRequest(v.method, ts.URL+v.path, bytes.NewBufferString(v.body))
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(res.Body)
+		res.Body.Close()
+		if res.StatusCode != v.code {
+			t.Errorf("wrong status %v for case %v, expected %v: %v", res.StatusCode, i, v.code, buf)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "application/json; charset=UTF-8" {
+			t.Errorf("wrong content type %q for case %v", ct, i)
+		}
+		if res.StatusCode != http.StatusOK {
+			apiErr := &APIError{}
+			if err := json.Unmarshal(buf.Bytes(), apiErr); err != nil || apiErr.Error.Code != v.code {
+				t.Errorf("wrong error response for case %v: %v", i, buf)
+			}
+		}
+		if v.want != "" && string(bytes.TrimSpace(buf.Bytes())) != v.want {
+			t.Errorf("wrong response for case %v: %v", i, buf)
+		}
+	}
+}
+
+func TestAPILookup(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/api/v1/lookup?dir=en-ru&word=time")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	response := &APILookupResponse{}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		t.Fatalf("JSON decode error: %v", err)
+	}
+	if len(response.Def) != 1 || response.Def[0].Tr[0].Text != "[time]" {
+		t.Errorf("wrong lookup response: %+v", response)
+	}
+
+	res, err = http.Get(ts.URL + "/api/v1/languages")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	langs := &Languages{}
+	if err := json.NewDecoder(res.Body).Decode(langs); err != nil {
+		t.Fatalf("JSON decode error: %v", err)
+	}
+	if fmt.Sprint(langs.Translation) != "[en-ru ru-en]" || langs.Names["en"] != "English" {
+		t.Errorf("wrong languages: %+v", langs)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/languages")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response := &LanguagesResponse{}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		t.Fatalf("JSON decode error: %v", err)
+	}
+	res.Body.Close()
+	// translation: en-ru, ru-en; dictionary: en-ru, ru-en, ru-ru
+	if n := len(response.Languages); n != 2 {
+		t.Fatalf("wrong number of groups: %+v", response.Languages)
+	}
+	ru := response.Languages[1]
+	if ru.Code != "ru" || ru.Name != "Russian" || len(ru.Translation) != 1 || len(ru.Dictionary) != 2 {
+		t.Errorf("wrong group: %+v", ru)
+	}
+	if ru.Translation[0] != (LanguageTarget{Code: "en", Name: "English"}) {
+		t.Errorf("wrong target: %+v", ru.Translation[0])
+	}
+	tag, modified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+	if tag == "" || modified == "" {
+		t.Fatalf("empty cache headers: %q, %q", tag, modified)
+	}
+
+	// refresh with the same languages keeps cache headers
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	for _, path := range []string{"/languages", "/api/v1/languages"} {
+		req, err := http.NewRequest("GET", ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		req.Header.Set("If-None-Match", tag)
+		res, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusNotModified {
+			t.Errorf("wrong status %v for %v", res.StatusCode, path)
+		}
+	}
+	req, err := http.NewRequest("GET", ts.URL+"/languages", nil)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	req.Header.Set("If-Modified-Since", modified)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("wrong status %v for If-Modified-Since", res.StatusCode)
+	}
+}
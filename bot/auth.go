@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SignatureHeader is HTTP header with HMAC-SHA256 signature of a request.
+	SignatureHeader = "X-Signature"
+	// TimestampHeader is HTTP header with signature's unix timestamp (seconds).
+	TimestampHeader = "X-Timestamp"
+	// signaturePrefix is signature's algorithm prefix
+	signaturePrefix = "sha256="
+	// defaultAuthWindow is default replay window of signed requests
+	defaultAuthWindow = 5 * time.Minute
+	// minSecretLength is minimal length of signature secret
+	minSecretLength = 16
+)
+
+// AuthConfig is authentication settings of chat events, batch and REST API requests.
+// Token is a bearer token for "Authorization" header,
+// Secret is a key of HMAC-SHA256 signature, Window is a replay window (seconds).
+// If both token and secret are set, both checks are required.
+type AuthConfig struct {
+	Token  string `json:"token" yaml:"token" toml:"token"`
+	Secret string `json:"secret" yaml:"secret" toml:"secret"`
+	Window uint   `json:"window" yaml:"window" toml:"window"`
+}
+
+// window returns replay window duration.
+func (c *AuthConfig) window() time.Duration {
+	if c.Window == 0 {
+		return defaultAuthWindow
+	}
+	return time.Duration(c.Window) * time.Second
+}
+
+// validate returns authentication settings problems.
+func (c *AuthConfig) validate() []string {
+	if c.Secret != "" && len(c.Secret) < minSecretLength {
+		return []string{fmt.Sprintf("auth.secret: it is too short, use at least %v characters", minSecretLength)}
+	}
+	return nil
+}
+
+// Sign returns signature of a request for SignatureHeader.
+// It is HMAC-SHA256 of "timestamp.method.uri.body" string,
+// where uri is request's path with raw query like "/api/v1/lookup?dir=en-ru&word=time".
+func Sign(secret string, timestamp int64, method, uri string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%v.%v.", timestamp, method, uri)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// replays stores used signatures during replay window.
+type replays struct {
+	sync.Mutex
+	seen map[string]time.Time
+}
+
+// check returns false if the signature was already used,
+// otherwise it saves the signature until expiration time.
+func (rp *replays) check(signature string, now, expire time.Time) bool {
+	rp.Lock()
+	defer rp.Unlock()
+	if rp.seen == nil {
+		rp.seen = make(map[string]time.Time)
+	}
+	for sig, t := range rp.seen {
+		if now.After(t) {
+			delete(rp.seen, sig)
+		}
+	}
+	if _, ok := rp.seen[signature]; ok {
+		return false
+	}
+	rp.seen[signature] = expire
+	return true
+}
+
+// authenticate checks request's bearer token and signature.
+func (s *Service) authenticate(cfg *AuthConfig, r *http.Request, body []byte) error {
+	if cfg.Token != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+			return errors.New("invalid bearer token")
+		}
+	}
+	if cfg.Secret == "" {
+		return nil
+	}
+	signature := r.Header.Get(SignatureHeader)
+	if signature == "" {
+		return errors.New("request signature is required")
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("invalid signature timestamp")
+	}
+	now, signed := time.Now(), time.Unix(timestamp, 0)
+	window := cfg.window()
+	if d := now.Sub(signed); d > window || d < -window {
+		return errors.New("signature is expired")
+	}
+	expected := Sign(cfg.Secret, timestamp, r.Method, r.URL.RequestURI(), body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errors.New("invalid signature")
+	}
+	if !s.replays.check(signature, now, signed.Add(window)) {
+		return errors.New("signature is already used")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+	const (
+		token  = "test-token"
+		secret = "0123456789abcdef"
+	)
+	upstream := upTestServices(t)
+	defer upstream.Close()
+	s := newTestService(upstream.URL)
+	s.current().cfg.Auth = AuthConfig{Token: token, Secret: secret, Window: 60}
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	body := []byte(`{"text": "en-ru some words"}`)
+	bearer := "Bearer " + token
+	now := time.Now().Unix()
+	signature := Sign(secret, now, "POST", "/event", body)
+	testValues := []struct {
+		name      string
+		auth      string
+		timestamp int64
+		signature string
+		code      int
+	}{
+		{"no token", "", now, signature, http.StatusUnauthorized},
+		{"wrong token", "Bearer bad", now, signature, http.StatusUnauthorized},
+		{"raw token", token, now, signature, http.StatusUnauthorized},
+		{"other scheme", "Basic " + token, now, signature, http.StatusUnauthorized},
+		{"no signature", bearer, now, "", http.StatusUnauthorized},
+		{"wrong signature", bearer, now, Sign("wrong secret value", now, "POST", "/event", body), http.StatusUnauthorized},
+		{"other timestamp", bearer, now + 1, signature, http.StatusUnauthorized},
+		{"expired", bearer, now - 120, Sign(secret, now-120, "POST", "/event", body), http.StatusUnauthorized},
+		{"valid", bearer, now, signature, http.StatusCreated},
+		{"replay", bearer, now, signature, http.StatusUnauthorized},
+		{"other path", bearer, now, Sign(secret, now, "POST", "/translate/batch", body), http.StatusUnauthorized},
+	}
+	for _, v := range testValues {
+		req, err := http.NewRequest("POST", ts.URL+"/event", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.auth != "" {
+			req.Header.Set("Authorization", v.auth)
+		}
+		if v.signature != "" {
+			req.Header.Set(SignatureHeader, v.signature)
+		}
+		req.Header.Set(TimestampHeader, strconv.FormatInt(v.timestamp, 10))
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", v.name, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != v.code {
+			t.Errorf("%v: wrong status %v, expected %v", v.name, res.StatusCode, v.code)
+		}
+	}
+}
+
+func TestAuthenticateQuery(t *testing.T) {
+	t.Parallel()
+	const secret = "0123456789abcdef"
+	upstream := upTestServices(t)
+	defer upstream.Close()
+	s := newTestService(upstream.URL)
+	s.current().cfg.Auth = AuthConfig{Secret: secret}
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	const uri = "/api/v1/lookup?dir=en-ru&word=time"
+	now := time.Now().Unix()
+	testValues := []struct {
+		name      string
+		uri       string
+		signature string
+		code      int
+	}{
+		{"other query", "/api/v1/lookup?dir=en-ru&word=other", Sign(secret, now, "GET", uri, nil), http.StatusUnauthorized},
+		{"other method", uri, Sign(secret, now, "POST", uri, nil), http.StatusUnauthorized},
+		{"valid", uri, Sign(secret, now, "GET", uri, nil), http.StatusOK},
+	}
+	for _, v := range testValues {
+		req, err := http.NewRequest("GET", ts.URL+v.uri, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(SignatureHeader, v.signature)
+		req.Header.Set(TimestampHeader, strconv.FormatInt(now, 10))
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", v.name, err)
+		}
+		res.Body.Close()
+		if res.StatusCode != v.code {
+			t.Errorf("%v: wrong status %v, expected %v", v.name, res.StatusCode, v.code)
+		}
+	}
+}
+
+func TestAuthValidate(t *testing.T) {
+	cfg := &AuthConfig{Secret: "short"}
+	if n := len(cfg.validate()); n != 1 {
+		t.Errorf("wrong number of problems: %v", n)
+	}
+	cfg.Secret = "0123456789abcdef"
+	if n := len(cfg.validate()); n != 0 {
+		t.Errorf("wrong number of problems: %v", n)
+	}
+}
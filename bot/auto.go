@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// maxRecentReplies is a number of recent automatic translations which are kept for loop protection
+const maxRecentReplies = 100
+
+// AutoConfig is automatic translation settings.
+// Users are usernames with target languages, their messages are translated
+// without "xx-yy" commands. Admins can manage automatic translation of other
+// users by chat commands. Messages of Ignore users and the bot itself
+// are never translated automatically.
+type AutoConfig struct {
+	Users  map[string]string `json:"users" yaml:"users" toml:"users"`
+	Admins []string          `json:"admins" yaml:"admins" toml:"admins"`
+	Ignore []string          `json:"ignore" yaml:"ignore" toml:"ignore"`
+}
+
+// isAdmin returns true if username can manage automatic translation of other users.
+func (c *AutoConfig) isAdmin(username string) bool {
+	return contains(c.Admins, username)
+}
+
+// isIgnored returns true if messages of username are never translated automatically.
+func (c *AutoConfig) isIgnored(username string) bool {
+	return username == Name || contains(c.Ignore, username)
+}
+
+// contains returns true if items contain value.
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// autoTranslate keeps hashes of recent automatic translations to not translate them again.
+type autoTranslate struct {
+	sync.Mutex
+	replies map[[sha256.Size]byte]struct{}
+	order   [][sha256.Size]byte
+}
+
+// newAutoTranslate returns new automatic translation state.
+func newAutoTranslate() *autoTranslate {
+	return &autoTranslate{replies: make(map[[sha256.Size]byte]struct{})}
+}
+
+// reply remembers automatic translation.
+func (a *autoTranslate) reply(text string) {
+	if text == "" {
+		return
+	}
+	key := sha256.Sum256([]byte(text))
+	a.Lock()
+	defer a.Unlock()
+	if _, ok := a.replies[key]; ok {
+		return
+	}
+	if len(a.order) >= maxRecentReplies {
+		delete(a.replies, a.order[0])
+		a.order = a.order[1:]
+	}
+	a.replies[key] = struct{}{}
+	a.order = append(a.order, key)
+}
+
+// isReply returns true if the text is a recent automatic translation.
+func (a *autoTranslate) isReply(text string) bool {
+	key := sha256.Sum256([]byte(text))
+	a.Lock()
+	defer a.Unlock()
+	_, ok := a.replies[key]
+	return ok
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAutoTranslate(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	cfg := *s.current().cfg
+	cfg.Auto = AutoConfig{
+		Users:  map[string]string{"alice": "ru", "bot": "ru"},
+		Admins: []string{"admin"},
+		Ignore: []string{"bot"},
+	}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	events := []struct {
+		username string
+		text     string
+		want     string
+	}{
+		{"alice", "hello", "[hello]"},
+		{"alice", "en-ru -t hi", "[hi]"},
+		{"bob", "hello", ""},
+		{"bob", "tr auto en", "automatic translation to en is enabled for bob"},
+		{"bob", "привет", "[привет]"},
+		// command replies are not ignored
+		{"carol", "ru-en -t привет мир", "[привет мир]"},
+		{"bob", "[привет мир]", "[[привет мир]]"},
+		{"bob", "tr auto off alice", "only admins can change automatic translation of other users"},
+		{"bob", "tr auto xx", "unknown target language xx"},
+		{"bob", "tr help", commandUsage},
+		{"bob", "tr is not a command", "[tr is not a command]"},
+		{"admin", "tr auto off @alice", "automatic translation is disabled for alice"},
+		{"alice", "hello again", ""},
+		{"bot", "ignored", ""},
+		{Name, "own message", ""},
+		// automatic translation is not translated again
+		{"bob", "[привет]", ""},
+	}
+	for i, e := range events {
+		result, err := s.HandleEvent(ctx, &EventRequest{Username: e.username, Text: e.text})
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", i, err)
+		}
+		if result != e.want {
+			t.Errorf("wrong result for %v: %q, expected %q", i, result, e.want)
+		}
+	}
+}
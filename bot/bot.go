@@ -0,0 +1,676 @@
+// Package bot is Radio-t chat translation bot.
+// It translates required sentences or words using Yandex translate API.
+package bot
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+const (
+	// Name is a program name
+	Name = "translation-bot"
+	// Author is author email
+	Author = "thebestzorro@yandex.ru"
+	// ConfigName is default configuration file name
+	ConfigName = "config.json"
+	// defaultTimeout is default configuration timeout (seconds)
+	defaultTimeout = 3 * time.Second
+	// defaultRefreshCheck is a period to check that languages refresh is enabled
+	defaultRefreshCheck = time.Minute
+)
+
+// Service is a translation bot instance.
+// It owns its configuration, API clients and loaded languages,
+// so several isolated services can work in one process.
+type Service struct {
+	mu          sync.RWMutex
+	st          *state
+	replays     replays
+	auto        *autoTranslate
+	prefs       *prefsStore
+	stats       *expvar.Map
+	trLangs     []string
+	dictLangs   []string
+	langNames   map[string]string
+	langsTag    string
+	langsTime   time.Time
+	loggerInfo  *log.Logger
+	loggerError *log.Logger
+}
+
+// state is a set of values which are replaced together on configuration reload.
+type state struct {
+	cfg        *Config
+	httpClient *http.Client
+	tr         *translator.Client
+	dict       *translator.Client
+	cache      *cache
+	history    *history
+	glossary   *glossaries
+	limiter    *limiter
+}
+
+// client returns API client and provider name for the translation mode.
+func (st *state) client(isTr bool) (*translator.Client, string) {
+	if isTr {
+		return st.tr, st.cfg.Routing.Translate
+	}
+	return st.dict, st.cfg.Routing.Dictionary
+}
+
+// NewService returns new translation service.
+func NewService(cfg *Config, loggerInfo, loggerError *log.Logger) (*Service, error) {
+	s := &Service{
+		auto:        newAutoTranslate(),
+		prefs:       newPrefsStore(),
+		stats:       newStats(),
+		loggerInfo:  loggerInfo,
+		loggerError: loggerError,
+	}
+	cfg.normalize()
+	st, err := s.newState(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.st = st
+	if err := s.st.cache.load(cfg.Cache.File); err != nil {
+		loggerError.Printf("cache load error: %v", err)
+	}
+	if err := s.loadUsage(cfg.Limits.UsageFile); err != nil {
+		loggerError.Printf("usage counters load error: %v", err)
+	}
+	if err := s.prefs.load(cfg.Prefs.File); err != nil {
+		loggerError.Printf("preferences load error: %v", err)
+	}
+	return s, nil
+}
+
+// newState returns HTTP and API clients, cache, history, glossaries and limiter for the configuration.
+// The cache, history, glossaries, limiter and HTTP client of old state are kept if their settings are not changed,
+// the limiter depends on rate and burst only.
+func (s *Service) newState(cfg *Config, old *state) (*state, error) {
+	st := &state{cfg: cfg}
+	if old != nil && old.cfg.Limits.Rate == cfg.Limits.Rate && old.cfg.Limits.Burst == cfg.Limits.Burst {
+		// keep limiter's tokens, reloads don't refill the bucket
+		st.limiter = old.limiter
+	} else {
+		st.limiter = newLimiter(cfg.Limits)
+	}
+	if old != nil && old.cfg.Transport == cfg.Transport {
+		// keep HTTP client to reuse opened connections
+		st.httpClient = old.httpClient
+	} else {
+		httpClient, err := newHTTPClient(cfg.Transport)
+		if err != nil {
+			return nil, err
+		}
+		st.httpClient = httpClient
+	}
+	if old != nil && old.cache != nil && old.cfg.Cache == cfg.Cache {
+		st.cache = old.cache
+	} else {
+		st.cache = newCache(cfg.Cache)
+	}
+	if old != nil && old.history != nil && old.cfg.History == cfg.History {
+		st.history = old.history
+	} else {
+		st.history = newHistory(cfg.History)
+	}
+	if old != nil && sameGlossaries(&old.cfg.Glossary, &cfg.Glossary) {
+		st.glossary = old.glossary
+	} else {
+		glossary, err := newGlossaries(cfg.Glossary)
+		if err != nil {
+			return nil, err
+		}
+		st.glossary = glossary
+	}
+	st.tr = newClient(cfg, cfg.Providers[cfg.Routing.Translate], st.httpClient)
+	st.dict = newClient(cfg, cfg.Providers[cfg.Routing.Dictionary], st.httpClient)
+	return st, nil
+}
+
+// newClient returns API client for the provider.
+func newClient(cfg *Config, p *ProviderConfig, httpClient *http.Client) *translator.Client {
+	if p == nil {
+		p = &ProviderConfig{}
+	}
+	client := translator.NewClient(p.provider(), p.TranslationKey, p.DictionaryKey, cfg.timeout)
+	client.HTTPClient = httpClient
+	client.UserAgent = Name
+	return client
+}
+
+// current returns actual configuration, API clients and cache.
+// In-flight requests keep using the values they got, even if they are reloaded.
+func (s *Service) current() *state {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.st
+}
+
+// Config returns actual configuration.
+func (s *Service) Config() *Config {
+	return s.current().cfg
+}
+
+// Reload validates new configuration and replaces the current one.
+// The current configuration is kept if new one is invalid, the address
+// and TLS files of the running server are kept until restart.
+func (s *Service) Reload(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("empty configuration")
+	}
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, err := s.newState(cfg, s.st)
+	if err != nil {
+		return err
+	}
+	if s.st.cfg.Addr() != cfg.Addr() {
+		// the server listens the startup address
+		s.loggerError.Printf("address can't be changed without restart, old one is kept: %v", s.st.cfg.Addr())
+		cfg.Host, cfg.Port = s.st.cfg.Host, s.st.cfg.Port
+	}
+	if old := &s.st.cfg.TLS; !old.sameFiles(&cfg.TLS) {
+		// the server and its certificates reloader use startup files, client certificates checks must match them
+		s.loggerError.Println("HTTPS can't be enabled, disabled or change tls.cert, tls.key, tls.client_ca without restart, old ones are kept")
+		cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.ClientCA = old.Cert, old.Key, old.ClientCA
+	}
+	if st.httpClient != s.st.httpClient {
+		// in-flight requests keep their connections
+		s.st.httpClient.CloseIdleConnections()
+	}
+	s.st = st
+	return nil
+}
+
+// InitLanguages initializes languages arrays
+func (s *Service) InitLanguages(ctx context.Context) error {
+	st := s.current()
+	trLangs, err := st.tr.TranslationLangs(ctx)
+	if err != nil {
+		return err
+	}
+	dictLangs, err := st.dict.DictionaryLangs(ctx)
+	if err != nil {
+		return err
+	}
+	tag := langsTag(trLangs, dictLangs)
+	s.mu.Lock()
+	s.trLangs, s.dictLangs = trLangs.Content(), dictLangs.Content()
+	s.langNames = trLangs.Langs
+	if tag != s.langsTag {
+		// modification time is changed only if refresh has loaded new values
+		s.langsTag, s.langsTime = tag, time.Now().UTC()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Languages is a set of loaded languages directions and names.
+type Languages struct {
+	Translation []string          `json:"translation"`
+	Dictionary  []string          `json:"dictionary"`
+	Names       map[string]string `json:"names"`
+}
+
+// Languages returns loaded languages directions and names.
+func (s *Service) Languages() *Languages {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	langs := &Languages{
+		Translation: append([]string{}, s.trLangs...),
+		Dictionary:  append([]string{}, s.dictLangs...),
+		Names:       make(map[string]string, len(s.langNames)),
+	}
+	for code, name := range s.langNames {
+		langs.Names[code] = name
+	}
+	return langs
+}
+
+// Refresh reloads languages periodically until ctx is done.
+// The period is read from actual configuration, zero value disables reloads.
+func (s *Service) Refresh(ctx context.Context) {
+	for {
+		period := time.Duration(s.current().cfg.Refresh) * time.Second
+		if period == 0 {
+			period = defaultRefreshCheck
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(period):
+			if s.current().cfg.Refresh == 0 {
+				continue
+			}
+			if err := s.InitLanguages(ctx); err != nil && ctx.Err() == nil {
+				s.loggerError.Printf("languages refresh error, old ones are kept: %v", err)
+			}
+		}
+	}
+}
+
+// Close saves the cache, usage counters and users preferences to their files.
+// All of them are saved even if some saves fail, the errors are joined.
+func (s *Service) Close() error {
+	st := s.current()
+	var errs []error
+	if err := st.cache.save(st.cfg.Cache.File); err != nil {
+		errs = append(errs, fmt.Errorf("cache save error: %v", err))
+	}
+	if err := s.saveUsage(st.cfg.Limits.UsageFile); err != nil {
+		errs = append(errs, fmt.Errorf("usage counters save error: %v", err))
+	}
+	if err := s.prefs.save(st.cfg.Prefs.File); err != nil {
+		errs = append(errs, fmt.Errorf("preferences save error: %v", err))
+	}
+	return errors.Join(errs...)
+}
+
+// maxSuggestions is maximum number of suggested directions
+const maxSuggestions = 3
+
+// suggest returns valid directions which are the closest to unknown direction.
+// Translation directions are suggested for the dictionary mode too,
+// because a word is translated if dictionary doesn't have its direction.
+func (s *Service) suggest(direction string, isTr bool) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if isTr {
+		return translator.Suggest(s.trLangs, direction, maxSuggestions)
+	}
+	languages := make([]string, 0, len(s.trLangs)+len(s.dictLangs))
+	languages = append(languages, s.dictLangs...)
+	for _, d := range s.trLangs {
+		if !translator.IsDirection(s.dictLangs, d) {
+			languages = append(languages, d)
+		}
+	}
+	sort.Strings(languages)
+	return translator.Suggest(languages, direction, maxSuggestions)
+}
+
+// suggestMessage returns a reply with suggested directions, it's empty if there are no suggestions.
+func (s *Service) suggestMessage(direction string, isTr bool) string {
+	suggestions := s.suggest(direction, isTr)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("unknown direction %v, try: %v", direction, strings.Join(suggestions, ", "))
+}
+
+// unknownDirection returns an error for unknown direction with suggested directions.
+func (s *Service) unknownDirection(direction string, isTr bool) error {
+	mode := "translation"
+	if !isTr {
+		mode = "dictionary"
+	}
+	msg := fmt.Sprintf("unknown %v direction %q", mode, direction)
+	if suggestions := s.suggest(direction, isTr); len(suggestions) > 0 {
+		msg += ", try: " + strings.Join(suggestions, ", ")
+	}
+	return &httpError{code: http.StatusBadRequest, msg: msg}
+}
+
+// isDirection checks - "direction" is language direction.
+func (s *Service) isDirection(direction string, isTr bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if isTr {
+		return translator.IsDirection(s.trLangs, direction)
+	}
+	return translator.IsDirection(s.dictLangs, direction)
+}
+
+// cacheKey returns cache key of the translation result.
+func cacheKey(isTr bool, direction, text string) string {
+	return fmt.Sprintf("%v:%v:%v", isTr, direction, text)
+}
+
+// getTranslation returns translation result: "translate" or dictionary.
+// Glossary terms of the direction are not translated.
+func (s *Service) getTranslation(ctx context.Context, isTr bool, direction, text string) (string, error) {
+	var (
+		result translator.Translater
+		values []string
+		err    error
+	)
+	st := s.current()
+	if isTr {
+		text, values = st.glossary.get(direction).Protect(text)
+	}
+	key := cacheKey(isTr, direction, text)
+	if value, ok := st.cache.get(key); ok {
+		return translator.Restore(value, values), nil
+	}
+	client, provider := st.client(isTr)
+	if isTr {
+		result, err = client.Translate(ctx, direction, text)
+	} else {
+		result, err = client.Lookup(ctx, direction, text)
+	}
+	if err != nil {
+		return "", err
+	}
+	s.usage().Add(provider, int64(utf8.RuneCountInString(text)))
+	value := result.String()
+	st.cache.set(key, value)
+	return translator.Restore(value, values), nil
+}
+
+// Translate is a main translation method.
+// It returns translated result and error value.
+// Several commands of the message are translated concurrently,
+// their results are joined in the order of commands.
+func (s *Service) Translate(ctx context.Context, text string) (string, error) {
+	return s.translate(ctx, text, &Preferences{})
+}
+
+// translate translates commands of the text with user's preferences.
+func (s *Service) translate(ctx context.Context, text string, p *Preferences) (string, error) {
+	commands := translator.ParseAll(text)
+	if len(commands) == 0 {
+		return "", nil
+	}
+	limits := &s.current().cfg.Limits
+	for _, cmd := range commands {
+		if !cmd.Forced {
+			cmd.IsTr = p.mode(limits, cmd.Text, cmd.IsTr)
+		}
+	}
+	if max := limits.commandsLimit(); len(commands) > max {
+		return "", &httpError{
+			code: http.StatusBadRequest,
+			msg:  fmt.Sprintf("too many commands %v, maximum is %v", len(commands), max),
+		}
+	}
+	for _, cmd := range commands {
+		if err := limits.checkText(cmd.Text, cmd.IsTr); err != nil {
+			return "", err
+		}
+	}
+	if len(commands) == 1 {
+		return s.translateCommand(ctx, commands[0], p)
+	}
+	var wg sync.WaitGroup
+	results := make([]string, len(commands))
+	errs := make([]error, len(commands))
+	for i, cmd := range commands {
+		wg.Add(1)
+		go func(i int, cmd *translator.Command) {
+			defer wg.Done()
+			results[i], errs[i] = s.translateCommand(ctx, cmd, p)
+		}(i, cmd)
+	}
+	wg.Wait()
+	replies := make([]string, 0, len(commands))
+	for i, cmd := range commands {
+		if errs[i] != nil {
+			s.loggerError.Printf("translation error of %v command: %v", cmd.Direction, errs[i])
+			continue
+		}
+		if results[i] != "" {
+			replies = append(replies, fmt.Sprintf("[%v] %v", cmd.Direction, results[i]))
+		}
+	}
+	if len(replies) == 0 {
+		// all commands are failed or have empty results
+		for _, err := range errs {
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	return strings.Join(replies, translator.Separator), nil
+}
+
+// HandleEvent returns a reply to a chat message. Commands like "en-ru ^" or "ru ^username"
+// translate the last message of the channel or the last message of the user,
+// "tr ..." commands change bot's settings, other messages without commands
+// are saved to the history and translated automatically if it's enabled for the user.
+// Bot's own messages are ignored.
+func (s *Service) HandleEvent(ctx context.Context, event *EventRequest) (string, error) {
+	if event.Username == Name {
+		return "", nil
+	}
+	return s.handleEvent(ctx, s.current(), event)
+}
+
+// handleEvent returns a reply to a chat message.
+func (s *Service) handleEvent(ctx context.Context, st *state, event *EventRequest) (string, error) {
+	if args, ok := parseBotCommand(event.Text); ok {
+		return s.botCommand(event, args), nil
+	}
+	p := s.prefs.get(event.Username)
+	return s.handleMessage(ctx, st, event, &p)
+}
+
+// handleMessage returns a reply to a chat message with user's preferences.
+// User's default direction is used for "^username" and "tr text" commands.
+// A message typed with wrong keyboard layout is corrected before saving to the history.
+func (s *Service) handleMessage(ctx context.Context, st *state, event *EventRequest, p *Preferences) (string, error) {
+	// "^" without direction is a command only if the user has default direction
+	if direction, username, ok := parseHistoryCommand(event.Text); ok && (direction != "" || p.Direction != "") {
+		if st.history == nil {
+			return "", nil
+		}
+		if direction == "" {
+			direction = p.Direction
+		}
+		text, found := st.history.last(event.Channel, username)
+		if !found {
+			if username == "" {
+				return "no recent messages", nil
+			}
+			return fmt.Sprintf("no recent messages of %v", username), nil
+		}
+		return s.translateHistory(ctx, direction, text, p)
+	}
+	if len(translator.ParseAll(event.Text)) > 0 {
+		return s.translate(ctx, event.Text, p)
+	}
+	if text := strings.TrimPrefix(event.Text, commandPrefix+" "); p.Direction != "" && text != event.Text {
+		return s.translate(ctx, p.Direction+" "+text, p)
+	}
+	if text, ok := fixLayout(&st.cfg.Layout, event.Text); ok && !st.cfg.Auto.isIgnored(event.Username) {
+		fixed := *event
+		fixed.Text = text
+		st.history.add(event.Channel, event.Username, text)
+		result, err := s.autoTranslate(ctx, st, &fixed, p)
+		if err != nil || result != "" {
+			return result, err
+		}
+		return text, nil
+	}
+	st.history.add(event.Channel, event.Username, event.Text)
+	return s.autoTranslate(ctx, st, event, p)
+}
+
+// autoTranslate translates a message if automatic translation is enabled for its user.
+// The source language is detected by provider, a text in the target language isn't replied.
+// Recent automatic translations are not translated again to avoid loops.
+func (s *Service) autoTranslate(ctx context.Context, st *state, event *EventRequest, p *Preferences) (string, error) {
+	if st.cfg.Auto.isIgnored(event.Username) || s.auto.isReply(event.Text) {
+		return "", nil
+	}
+	target := p.autoTarget(&st.cfg.Auto, event.Username)
+	if target == "" || strings.TrimSpace(event.Text) == "" {
+		return "", nil
+	}
+	if err := st.cfg.Limits.checkText(event.Text, true); err != nil {
+		return "", err
+	}
+	result, err := s.getTranslation(ctx, true, target, event.Text)
+	if err != nil {
+		return "", err
+	}
+	if result == event.Text {
+		return "", nil
+	}
+	s.auto.reply(result)
+	return result, nil
+}
+
+// translateHistory translates a message from the history. If direction is
+// a single language, it's a target one and the source language is detected by provider.
+func (s *Service) translateHistory(ctx context.Context, direction, text string, p *Preferences) (string, error) {
+	if strings.Contains(direction, "-") {
+		limits := &s.current().cfg.Limits
+		cmd := &translator.Command{Direction: direction, Text: text, IsTr: p.mode(limits, text, translator.IsSentence(text))}
+		if err := limits.checkText(cmd.Text, cmd.IsTr); err != nil {
+			return "", err
+		}
+		return s.translateCommand(ctx, cmd, p)
+	}
+	if err := s.current().cfg.Limits.checkText(text, true); err != nil {
+		return "", err
+	}
+	if !s.isTarget(direction) {
+		s.loggerInfo.Printf("is not a target language: %v", direction)
+		return fmt.Sprintf("unknown target language %v", direction), nil
+	}
+	return s.getTranslation(ctx, true, direction, text)
+}
+
+// isTarget checks - "language" is a target language of some translation direction.
+func (s *Service) isTarget(language string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, direction := range s.trLangs {
+		if strings.HasSuffix(direction, "-"+language) {
+			return true
+		}
+	}
+	return false
+}
+
+// translateCommand returns translation result of the command.
+// Transliteration directions like "ru-lat" and glossary words are handled locally,
+// dictionary articles are shortened by user's verbosity.
+func (s *Service) translateCommand(ctx context.Context, cmd *translator.Command, p *Preferences) (string, error) {
+	if isTranslit(cmd.Direction) {
+		return transliterate(cmd)
+	}
+	st := s.current()
+	cmd.Text = detransliterate(&st.cfg.Translit, cmd)
+	if !cmd.IsTr {
+		if article, ok := st.glossary.get(cmd.Direction).Lookup(cmd.Text); ok {
+			return p.article(article.String()), nil
+		}
+	}
+	if !s.isDirection(cmd.Direction, cmd.IsTr) {
+		if cmd.IsTr || !s.isDirection(cmd.Direction, true) {
+			s.loggerInfo.Printf("is not a direction: %v", cmd.Direction)
+			return s.suggestMessage(cmd.Direction, cmd.IsTr), nil
+		}
+		// dictionary doesn't have the direction, but the word can be translated
+		cmd.IsTr = true
+	}
+	result, err := s.getTranslation(ctx, cmd.IsTr, cmd.Direction, cmd.Text)
+	if err != nil {
+		return "", err
+	}
+	if result == "" && !cmd.IsTr && !cmd.Forced && s.isDirection(cmd.Direction, true) {
+		// dictionary doesn't know the text, it is translated
+		return s.getTranslation(ctx, true, cmd.Direction, cmd.Text)
+	}
+	if !cmd.IsTr {
+		return p.article(result), nil
+	}
+	return result, nil
+}
+
+// TranslateBatch returns translations of texts for the direction in the same order.
+// Cached results are reused, other texts are translated by as few API requests
+// as provider's size limit allows. Glossary terms of the direction are not translated.
+func (s *Service) TranslateBatch(ctx context.Context, direction string, texts []string) ([]string, error) {
+	var (
+		missed  []string
+		indexes []int
+		length  int
+	)
+	st := s.current()
+	for _, text := range texts {
+		if err := st.cfg.Limits.checkText(text, true); err != nil {
+			return nil, err
+		}
+	}
+	if !s.isDirection(direction, true) {
+		return nil, s.unknownDirection(direction, true)
+	}
+	glossary := st.glossary.get(direction)
+	values := make([][]string, len(texts))
+	result := make([]string, len(texts))
+	for i, text := range texts {
+		text, values[i] = glossary.Protect(text)
+		if value, ok := st.cache.get(cacheKey(true, direction, text)); ok {
+			result[i] = value
+			continue
+		}
+		missed = append(missed, text)
+		indexes = append(indexes, i)
+		length += utf8.RuneCountInString(text)
+	}
+	if len(missed) == 0 {
+		return restoreAll(result, values), nil
+	}
+	client, provider := st.client(true)
+	translations, err := client.TranslateBatch(ctx, direction, missed)
+	if err != nil {
+		return nil, err
+	}
+	s.usage().Add(provider, int64(length))
+	for i, value := range translations {
+		result[indexes[i]] = value
+		st.cache.set(cacheKey(true, direction, missed[i]), value)
+	}
+	return restoreAll(result, values), nil
+}
+
+// restoreAll replaces glossary placeholders of results by their values.
+func restoreAll(results []string, values [][]string) []string {
+	for i := range results {
+		results[i] = translator.Restore(results[i], values[i])
+	}
+	return results
+}
+
+// Lookup returns dictionary article of the word for the direction,
+// glossary entries override provider's articles.
+func (s *Service) Lookup(ctx context.Context, direction, word string) (*translator.JSONTrDict, error) {
+	st := s.current()
+	if err := st.cfg.Limits.checkText(word, false); err != nil {
+		return nil, err
+	}
+	if article, ok := st.glossary.get(direction).Lookup(word); ok {
+		return article, nil
+	}
+	if !s.isDirection(direction, false) {
+		return nil, s.unknownDirection(direction, false)
+	}
+	client, provider := st.client(false)
+	result, err := client.Lookup(ctx, direction, word)
+	if err != nil {
+		return nil, err
+	}
+	s.usage().Add(provider, int64(utf8.RuneCountInString(word)))
+	return result, nil
+}
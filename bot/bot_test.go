@@ -0,0 +1,714 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+	"github.com/z0rr0/transalation-bot/translator/providertest"
+)
+
+func upTestServices(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Logf("request %v", r.URL.Path)
+		switch r.URL.Path {
+		case "/tr.json/getLangs":
+			response := `{
+				"dirs": [
+					"en-ru",
+					"ru-pl",
+					"ru-hu"
+				],
+				"langs": {
+					"ru": "русский",
+					"en": "английский",
+					"pl": "польский"
+    		}}`
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, response)
+		case "/dicservice.json/getLangs":
+			response := `["ru-ru","ru-en","ru-pl","ru-uk","ru-de","ru-fr","ru-es",
+				"ru-it","ru-tr","en-ru","en-en","en-de","en-fr","en-es","en-it","en-tr",
+				"pl-ru","uk-ru","de-ru","de-en","fr-ru","fr-en","es-ru","es-en",
+				"it-ru","it-en","tr-ru","tr-en"]`
+			w.Header().Set("Content-Type", "application/json; chts.URLarset=UTF-8")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, response)
+		case "/tr.json/translate":
+			response := `{
+				"code": 200,
+				"lang": "en-ru",
+				"text": [
+					"Здравствуй, Мир!"
+				]
+			}`
+			w.Header().Set("Content-Type", "application/json; chts.URLarset=UTF-8")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, response)
+		case "/dicservice.json/lookup":
+			if r.FormValue("text") == "nonexistent" {
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				fmt.Fprint(w, `{"head": {}, "def": []}`)
+				return
+			}
+			response := `
+			{ "head": {},
+				"def": [
+				 { "text": "time", "pos": "noun",
+				   "tr": [
+					  { "text": "время", "pos": "существительное",
+						"syn": [
+						   { "text": "раз" },
+						   { "text": "тайм" }
+						],
+						"mean": [
+						   { "text": "timing" },
+						   { "text": "fold" },
+						   { "text": "half"}
+						],
+						"ex" : [
+						   { "text": "prehistoric time",
+							 "tr": [
+								{ "text": "доисторическое время" }
+							 ]
+						   },
+						   { "text": "hundredth time",
+							 "tr": [
+								{ "text": "сотый раз" }
+							 ]
+						   },
+						   { "text": "time-slot",
+							 "tr": [
+								{ "text": "тайм-слот" }
+							 ]
+						   }
+						]
+					  }
+				   ]
+				}
+			]}`
+			w.Header().Set("Content-Type", "application/json; chts.URLarset=UTF-8")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, response)
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	return ts
+}
+
+// newTestService returns a service which uses baseURL as translation provider.
+func newTestService(baseURL string) *Service {
+	cfg := &Config{
+		Port: 8080,
+		Providers: map[string]*ProviderConfig{
+			"yandex": {
+				TranslationKey: "test",
+				DictionaryKey:  "test",
+				TranslateURL:   baseURL + "/tr.json",
+				DictionaryURL:  baseURL + "/dicservice.json",
+			},
+		},
+	}
+	logger := log.New(ioutil.Discard, "", 0)
+	s, err := NewService(cfg, logger, logger)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func TestInfo(t *testing.T) {
+	t.Parallel()
+	s := newTestService("")
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	res, err := http.Post(ts.URL+"/info", "application/json; charset=UTF-8", bytes.NewBufferString(""))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if s := res.StatusCode; s != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status: %v", s)
+	}
+
+	res, err = http.Get(ts.URL + "/info")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if s := res.StatusCode; s != http.StatusCreated {
+		t.Errorf("wrong status: %v", s)
+	}
+	decoder := json.NewDecoder(res.Body)
+	response := &InfoResponse{}
+	err = decoder.Decode(response)
+	if (err != nil) && (err != io.EOF) {
+		t.Errorf("JSON decode eror: %v", err)
+	}
+	res.Body.Close()
+	if a := response.Author; a != Author {
+		t.Errorf("wrong author: %v", a)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	t.Parallel()
+	testValues := map[string]struct {
+		Code int
+		Text string
+	}{
+		"":                           {http.StatusExpectationFailed, ""},
+		"text":                       {http.StatusExpectationFailed, ""},
+		"enru failed":                {http.StatusExpectationFailed, ""},
+		"zz-zz some text":            {http.StatusExpectationFailed, ""},
+		"en-ru dictionary":           {http.StatusCreated, "Здравствуй, Мир!"},
+		"en-ru translate some words": {http.StatusCreated, fmt.Sprintf("time%vвремя (существительное)", translator.Separator)},
+	}
+
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s := newTestService(upstream.URL)
+	err := s.InitLanguages(context.Background())
+	if err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	if len(s.trLangs) == 0 {
+		t.Fatal("empty tr langs")
+	}
+	if len(s.dictLangs) == 0 {
+		t.Fatal("empty dict langs")
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	for k, v := range testValues {
+		req := &EventRequest{
+			Text:        k,
+			Username:    "username",
+			DisplayName: "display name",
+		}
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Errorf("request marshal error: %v", err)
+		}
+		res, err := http.Post(ts.URL+"/event", "application/json; charset=UTF-8", bytes.NewBuffer(data))
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if s := res.StatusCode; s != v.Code {
+			t.Errorf("wrong status %v, expected %v", s, v.Code)
+		} else {
+			if v.Text != "" {
+				decoder := json.NewDecoder(res.Body)
+				jresp := &EventResponse{}
+				err = decoder.Decode(jresp)
+				if (err != nil) && (err != io.EOF) {
+					t.Errorf("JSON decode eror: %v", err)
+				}
+				if text := jresp.Text; text == v.Text {
+					t.Errorf("unexpected text for request: %v != %v", text, v.Text)
+				}
+			}
+		}
+		res.Body.Close()
+	}
+}
+
+func TestIsolatedServices(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s1, s2 := newTestService(upstream.URL), newTestService(upstream.URL)
+	if err := s1.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	if len(s2.trLangs) != 0 || len(s2.dictLangs) != 0 {
+		t.Error("languages are shared between services")
+	}
+	if !s1.isDirection("en-ru", true) {
+		t.Error("en-ru is not found")
+	}
+	if s2.isDirection("en-ru", true) {
+		t.Error("unexpected en-ru direction")
+	}
+}
+
+func TestReload(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s := newTestService(upstream.URL)
+	old := s.current()
+	if err := s.Reload(&Config{Port: 8080, TranslationKey: "new"}); err == nil {
+		t.Error("expected validation error")
+	}
+	if st := s.current(); st != old {
+		t.Error("invalid configuration is applied")
+	}
+	newCfg := &Config{Host: "localhost", Port: 9090, TranslationKey: "new", DictionaryKey: "new", TimeoutValue: 1}
+	if err := s.Reload(newCfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st := s.current()
+	if st.cfg != newCfg {
+		t.Error("configuration is not reloaded")
+	}
+	if st.tr.TranslationKey != "new" || st.tr.Timeout != time.Second {
+		t.Errorf("client is not reloaded: %+v", st.tr)
+	}
+	if st.tr.Provider.Translate != translator.Yandex().Translate {
+		t.Errorf("provider is not reloaded: %v", st.tr.Provider.Translate)
+	}
+	if old.tr.TranslationKey != "test" {
+		t.Error("in-flight client is changed")
+	}
+	// the server keeps listening the old address
+	if a := s.Config().Addr(); a != old.cfg.Addr() {
+		t.Errorf("address is changed without restart: %v", a)
+	}
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	if c := newCache(CacheConfig{}); c != nil {
+		t.Error("disabled cache is not nil")
+	}
+	c := newCache(CacheConfig{Size: 2})
+	c.set("a", "1")
+	c.set("b", "2")
+	if v, ok := c.get("a"); !ok || v != "1" {
+		t.Errorf("wrong cached value: %v", v)
+	}
+	c.set("c", "3")
+	if _, ok := c.get("b"); ok {
+		t.Error("least recently used value is not removed")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("value %v is not found", key)
+		}
+	}
+	c.ttl = time.Nanosecond
+	c.set("d", "4")
+	time.Sleep(time.Millisecond)
+	if _, ok := c.get("d"); ok {
+		t.Error("expired value is found")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+	if l := newLimiter(LimitsConfig{}); !l.allow() {
+		t.Error("disabled limiter rejects requests")
+	}
+	l := newLimiter(LimitsConfig{Rate: 1, Burst: 2})
+	if !l.allow() || !l.allow() {
+		t.Error("burst requests are rejected")
+	}
+	if l.allow() {
+		t.Error("request is allowed over limit")
+	}
+
+	// reload with the same limits doesn't refill the bucket
+	upstream := upTestServices(t)
+	defer upstream.Close()
+	s := newTestService(upstream.URL)
+	cfg := *s.Config()
+	cfg.Limits = LimitsConfig{Rate: 1, Burst: 1}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.current().limiter.allow() {
+		t.Error("burst request is rejected")
+	}
+	// other limits don't change the rate
+	same := cfg
+	same.Limits.Text, same.Limits.Commands = 100, 2
+	if err := s.Reload(&same); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.current().limiter.allow() {
+		t.Error("reload refills the limiter")
+	}
+	changed := cfg
+	changed.Limits.Burst = 2
+	if err := s.Reload(&changed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.current().limiter.allow() {
+		t.Error("changed limiter is not rebuilt")
+	}
+}
+
+func TestEventValidation(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s := newTestService(upstream.URL)
+	s.current().cfg.Limits.Text = 20
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	testValues := []struct {
+		body string
+		code int
+		msg  string
+	}{
+		{"", http.StatusBadRequest, "empty request"},
+		{"{", http.StatusBadRequest, "invalid JSON"},
+		{"{\"text\": \"en-ru \xff\xfe\"}", http.StatusBadRequest, "text is not valid UTF-8"},
+		{`{"text": "en-ru some very long long text"}`, http.StatusBadRequest, "text is too long"},
+		{`{"text": "en-ru some\u0000 words"}`, http.StatusCreated, ""},
+		{`{"text": "en-ru time"}`, http.StatusCreated, ""},
+	}
+	for _, v := range testValues {
+		res, err := http.Post(ts.URL+"/event", "application/json", bytes.NewBufferString(v.body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.StatusCode != v.code {
+			t.Errorf("%q: wrong status %v, expected %v", v.body, res.StatusCode, v.code)
+		}
+		if !strings.Contains(string(data), v.msg) {
+			t.Errorf("%q: wrong response %q, expected %q", v.body, data, v.msg)
+		}
+	}
+}
+
+func TestCleanText(t *testing.T) {
+	t.Parallel()
+	text, err := cleanText("a\x00b\tc\nd\u200b\x7f")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "ab\tc\nd\u200b" {
+		t.Errorf("wrong text: %q", text)
+	}
+	if _, err = cleanText("\xff"); err == nil {
+		t.Error("expected error for invalid UTF-8")
+	}
+}
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	s := newTestService(upstream.URL)
+	cfg := *s.Config()
+	cfg.Cache = CacheConfig{Size: 10, TTL: 60, File: filepath.Join(dir, "cache.json")}
+	cfg.Limits.UsageFile = filepath.Join(dir, "usage.json")
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.getTranslation(context.Background(), true, "en-ru", "hello world"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger := log.New(ioutil.Discard, "", 0)
+	restored, err := NewService(&cfg, logger, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := restored.current().cache.get("true:en-ru:hello world"); !ok {
+		t.Error("cache is not restored")
+	}
+	if n := restored.usage().Get("yandex").String(); n != "11" {
+		t.Errorf("wrong usage counter: %v", n)
+	}
+	// a failed cache save doesn't skip other files
+	if err := os.Remove(cfg.Limits.UsageFile); err != nil {
+		t.Fatal(err)
+	}
+	failed := cfg
+	failed.Cache.File = filepath.Join(dir, "missing", "cache.json")
+	if err := restored.Reload(&failed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := restored.Close(); err == nil || !strings.Contains(err.Error(), "cache save error") {
+		t.Errorf("expected cache save error: %v", err)
+	}
+	if _, err := os.Stat(cfg.Limits.UsageFile); err != nil {
+		t.Errorf("usage counters are not saved: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json.*")); len(files) > 0 {
+		t.Errorf("temporary files are not removed: %v", files)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+	s := newTestService("")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Refresh(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("refresh is not stopped")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s := newTestService(upstream.URL)
+	old := s.current()
+	cfg := *old.cfg
+	cfg.TimeoutValue = 1
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.current().httpClient != old.httpClient {
+		t.Error("HTTP client is not kept")
+	}
+	tuned := cfg
+	tuned.Transport = TransportConfig{MaxIdleConnsPerHost: 5, Proxy: "http://proxy:3128", DisableHTTP2: true}
+	if err := s.Reload(&tuned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st := s.current()
+	if st.httpClient == old.httpClient || st.tr.HTTPClient != st.httpClient {
+		t.Error("HTTP client is not replaced")
+	}
+	tr := st.httpClient.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 5 || tr.ForceAttemptHTTP2 {
+		t.Errorf("wrong transport settings: %+v", tr)
+	}
+	proxy, err := tr.Proxy(httptest.NewRequest("GET", "https://example.com", nil))
+	if err != nil || proxy.String() != "http://proxy:3128" {
+		t.Errorf("wrong proxy: %v, %v", proxy, err)
+	}
+	invalid := cfg
+	invalid.Transport = TransportConfig{Proxy: "proxy", CAFile: "/not/found/ca.pem"}
+	if err := s.Reload(&invalid); err == nil {
+		t.Error("expected validation error")
+	}
+}
+
+// newContractService returns a service with loaded languages which uses
+// contract test server as translation provider, its results are "[text]".
+func newContractService(t *testing.T) (*Service, *providertest.Server) {
+	upstream := providertest.NewServer(t, func(baseURL string) *translator.Provider {
+		return translator.NewYandex(baseURL+"/tr.json", baseURL+"/dicservice.json")
+	})
+	cfg := &Config{
+		Port: 8080,
+		Providers: map[string]*ProviderConfig{
+			"yandex": {
+				TranslationKey: providertest.TranslationKey,
+				DictionaryKey:  providertest.DictionaryKey,
+				TranslateURL:   upstream.URL + "/tr.json",
+				DictionaryURL:  upstream.URL + "/dicservice.json",
+			},
+		},
+		Cache: CacheConfig{Size: 10},
+	}
+	logger := log.New(ioutil.Discard, "", 0)
+	s, err := NewService(cfg, logger, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	return s, upstream
+}
+
+func TestTranslateBatch(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+
+	s.current().cache.set(cacheKey(true, "en-ru", "cached"), "из кеша")
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	testValues := []struct {
+		req  BatchRequest
+		code int
+		want []string
+	}{
+		{BatchRequest{"en-ru", []string{"one", "cached", "two"}}, http.StatusOK, []string{"[one]", "из кеша", "[two]"}},
+		{BatchRequest{"zz-zz", []string{"one"}}, http.StatusBadRequest, nil},
+		{BatchRequest{"en-ru", nil}, http.StatusBadRequest, nil},
+	}
+	for i, v := range testValues {
+		data, err := json.Marshal(v.req)
+		if err != nil {
+			t.Fatalf("request marshal error: %v", err)
+		}
+		res, err := http.Post(ts.URL+"/translate/batch", "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.StatusCode != v.code {
+			t.Errorf("wrong status %v for case %v, expected %v", res.StatusCode, i, v.code)
+		}
+		if v.want != nil {
+			response := &BatchResponse{}
+			if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+				t.Errorf("JSON decode eror: %v", err)
+			}
+			if fmt.Sprint(response.Texts) != fmt.Sprint(v.want) {
+				t.Errorf("wrong translations: %v", response.Texts)
+			}
+		}
+		res.Body.Close()
+	}
+	if _, ok := s.current().cache.get(cacheKey(true, "en-ru", "two")); !ok {
+		t.Error("batch translation is not cached")
+	}
+	if n := s.usage().Get("yandex").String(); n != "6" {
+		t.Errorf("wrong usage counter: %v", n)
+	}
+}
+
+func TestSuggestDirections(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s := newTestService(upstream.URL)
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	testValues := map[string]string{
+		"en-ua hello big world": "unknown direction en-ua, try: en-ru",
+		"pl-ru hello big world": "unknown direction pl-ru, try: ru-pl, en-ru",
+		"zz-zz some text":       "",
+		"re-do some text":       "",
+		// hyphenated words are not directions
+		"we go en-masse tonight": "",
+		"I like it-related jobs": "",
+		// dictionary doesn't have "ru-hu", it is translated
+		"ru-hu слово": "Здравствуй, Мир!",
+	}
+	for k, v := range testValues {
+		result, err := s.Translate(context.Background(), k)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+}
+
+func TestModeSelection(t *testing.T) {
+	t.Parallel()
+	upstream := upTestServices(t)
+	defer upstream.Close()
+
+	s := newTestService(upstream.URL)
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	article := fmt.Sprintf("time%vвремя (существительное)", translator.Separator)
+	testValues := map[string]string{
+		"en-ru time":             article,
+		"en-ru -t time":          "Здравствуй, Мир!",
+		"en-ru good time":        article,
+		"en-ru good time!":       "Здравствуй, Мир!",
+		"en-ru -d good old time": article,
+		// dictionary doesn't know the word, it is translated
+		"en-ru nonexistent":    "Здравствуй, Мир!",
+		"en-ru -d nonexistent": "",
+	}
+	for k, v := range testValues {
+		result, err := s.Translate(context.Background(), k)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+}
+
+func TestMultipleCommands(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	ctx := context.Background()
+
+	testValues := map[string]string{
+		"en-ru hello big world\nru-en привет\nбольшой мир": "[en-ru] [hello big world]\n[ru-en] [привет\nбольшой мир]",
+		"en-ru,de,ru -t hello": "[en-ru] [hello]\n[en-de] unknown direction en-de, try: en-ru\n[en-ru] [hello]",
+		"zz-zz one\nzz-yy two": "",
+	}
+	for k, v := range testValues {
+		result, err := s.Translate(ctx, k)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+	if _, err := s.Translate(ctx, "en-ru,de,fr,es,it,pl hello"); err == nil {
+		t.Error("expected error for too many commands")
+	}
+}
+
+func TestTransliteration(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	ctx := context.Background()
+
+	testValues := map[string]string{
+		"ru-lat Привет, мир":  "Privet, mir",
+		"lat-uk Kharkiv":      "Харків",
+		"ru-lat,en -t привет": "[ru-lat] privet\n[ru-en] [привет]",
+		"ru-en -t privet mir": "[privet mir]",
+		"de-lat hallo":        "",
+	}
+	for k, v := range testValues {
+		result, err := s.Translate(ctx, k)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+	s.current().cfg.Translit.Detransliterate = true
+	if result, err := s.Translate(ctx, "ru-en -t privet mir"); err != nil || result != "[привет мир]" {
+		t.Errorf("wrong detransliterated result: %q, %v", result, err)
+	}
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"container/list"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// writeFileAtomic writes data to a temporary file and renames it to the file,
+// so a failed write doesn't corrupt the previous content.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// cacheItem is a cached translation result.
+type cacheItem struct {
+	Key    string    `json:"key"`
+	Value  string    `json:"value"`
+	Expire time.Time `json:"expire"`
+}
+
+// cache is LRU cache of translation results with limited items lifetime.
+type cache struct {
+	sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List
+	items map[string]*list.Element
+}
+
+// newCache returns new cache, it is nil if the cache is disabled.
+func newCache(cfg CacheConfig) *cache {
+	if cfg.Size == 0 {
+		return nil
+	}
+	return &cache{
+		size:  int(cfg.Size),
+		ttl:   time.Duration(cfg.TTL) * time.Second,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns not expired cached value.
+func (c *cache) get(key string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	item := e.Value.(*cacheItem)
+	if c.ttl > 0 && time.Now().After(item.Expire) {
+		c.order.Remove(e)
+		delete(c.items, key)
+		return "", false
+	}
+	c.order.MoveToFront(e)
+	return item.Value, true
+}
+
+// set saves the value, the least recently used value is removed if the cache is full.
+func (c *cache) set(key, value string) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	expire := time.Now().Add(c.ttl)
+	if e, ok := c.items[key]; ok {
+		item := e.Value.(*cacheItem)
+		item.Value, item.Expire = value, expire
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheItem{Key: key, Value: value, Expire: expire})
+	if c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*cacheItem).Key)
+	}
+}
+
+// save writes not expired items to the file, from least to most recently used.
+func (c *cache) save(file string) error {
+	if c == nil || file == "" {
+		return nil
+	}
+	c.Lock()
+	items := make([]*cacheItem, 0, c.order.Len())
+	now := time.Now()
+	for e := c.order.Back(); e != nil; e = e.Prev() {
+		item := e.Value.(*cacheItem)
+		if c.ttl == 0 || now.Before(item.Expire) {
+			items = append(items, item)
+		}
+	}
+	data, err := json.Marshal(items)
+	c.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, data)
+}
+
+// load reads items from the file, a missing file is not an error.
+func (c *cache) load(file string) error {
+	if c == nil || file == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var items []*cacheItem
+	if err = json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	now := time.Now()
+	c.Lock()
+	defer c.Unlock()
+	for _, item := range items {
+		if c.ttl > 0 && now.After(item.Expire) {
+			continue
+		}
+		if _, ok := c.items[item.Key]; ok {
+			continue
+		}
+		c.items[item.Key] = c.order.PushFront(item)
+		if c.order.Len() > c.size {
+			e := c.order.Back()
+			c.order.Remove(e)
+			delete(c.items, e.Value.(*cacheItem).Key)
+		}
+	}
+	return nil
+}
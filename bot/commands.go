@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// commandPrefix is a prefix of bot's settings commands
+	commandPrefix = "tr"
+	// autoOff is an argument which disables automatic translation
+	autoOff = "off"
+	// commandUsage is a reply to unknown bot's command
+	commandUsage = "commands: tr auto <language|off> [username], " +
+		"tr set <default|verbosity|mode|auto> <value>, tr prefs, tr reset, tr layout [text], tr <text>"
+)
+
+// parseBotCommand returns arguments of a bot's command like "tr auto ru".
+// Messages like "tr is ..." are not commands, the first argument must be a known command name.
+func parseBotCommand(text string) ([]string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 || fields[0] != commandPrefix {
+		return nil, false
+	}
+	switch fields[1] {
+	case "auto", "set", "prefs", "reset", "layout", "help":
+		return fields[1:], true
+	}
+	return nil, false
+}
+
+// botCommand executes bot's command of the event and returns a reply.
+func (s *Service) botCommand(event *EventRequest, args []string) string {
+	switch args[0] {
+	case "auto":
+		return s.autoCommand(event, args[1:])
+	case "set":
+		if len(args) != 3 || event.Username == "" {
+			return commandUsage
+		}
+		return s.setPreference(event.Username, args[1], args[2])
+	case "prefs":
+		p := s.prefs.get(event.Username)
+		return fmt.Sprintf("%v preferences: %v", event.Username, p.String())
+	case "reset":
+		s.prefs.update(event.Username, func(p *Preferences) { *p = Preferences{} })
+		s.savePrefs()
+		return fmt.Sprintf("%v preferences are reset", event.Username)
+	case "layout":
+		return s.layoutCommand(event)
+	}
+	return commandUsage
+}
+
+// autoCommand enables or disables automatic translation: "tr auto ru" for the sender
+// or "tr auto off username" for other user, if the sender is admin.
+func (s *Service) autoCommand(event *EventRequest, args []string) string {
+	if len(args) == 0 || len(args) > 2 {
+		return commandUsage
+	}
+	target, username := args[0], event.Username
+	if len(args) == 2 {
+		username = strings.TrimPrefix(args[1], "@")
+		if username != event.Username && !s.current().cfg.Auto.isAdmin(event.Username) {
+			return "only admins can change automatic translation of other users"
+		}
+	}
+	if username == "" {
+		return "automatic translation requires a username"
+	}
+	if target != autoOff && !s.isTarget(target) {
+		return fmt.Sprintf("unknown target language %v", target)
+	}
+	s.prefs.update(username, func(p *Preferences) { p.Auto = target })
+	s.savePrefs()
+	if target == autoOff {
+		return fmt.Sprintf("automatic translation is disabled for %v", username)
+	}
+	return fmt.Sprintf("automatic translation to %v is enabled for %v", target, username)
+}
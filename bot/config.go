@@ -0,0 +1,396 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/z0rr0/transalation-bot/translator"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxPort is maximum TCP port number
+	maxPort = 65535
+	// maxTimeout is maximum upstream requests timeout (seconds)
+	maxTimeout = 300
+	// defaultShutdown is default period to drain in-flight requests on shutdown
+	defaultShutdown = 10 * time.Second
+	// providerYandex is Yandex provider type and default provider name
+	providerYandex = "yandex"
+)
+
+// ProviderConfig is translation provider settings.
+// Empty URLs mean default provider's API URLs.
+// Encoding is "form" (default), "query" or "json". The API key is sent
+// as KeyParam parameter ("key" by default) or in AuthHeader after AuthPrefix.
+type ProviderConfig struct {
+	Type           string `json:"type" yaml:"type" toml:"type"`
+	TranslationKey string `json:"tkey" yaml:"tkey" toml:"tkey"`
+	DictionaryKey  string `json:"dkey" yaml:"dkey" toml:"dkey"`
+	TranslateURL   string `json:"translate_url" yaml:"translate_url" toml:"translate_url"`
+	DictionaryURL  string `json:"dictionary_url" yaml:"dictionary_url" toml:"dictionary_url"`
+	Encoding       string `json:"encoding" yaml:"encoding" toml:"encoding"`
+	KeyParam       string `json:"key_param" yaml:"key_param" toml:"key_param"`
+	AuthHeader     string `json:"auth_header" yaml:"auth_header" toml:"auth_header"`
+	AuthPrefix     string `json:"auth_prefix" yaml:"auth_prefix" toml:"auth_prefix"`
+}
+
+// RoutingConfig contains providers names by translation mode.
+type RoutingConfig struct {
+	Translate  string `json:"translate" yaml:"translate" toml:"translate"`
+	Dictionary string `json:"dictionary" yaml:"dictionary" toml:"dictionary"`
+}
+
+// CacheConfig is translation results cache settings.
+// Zero size disables the cache, zero TTL (seconds) means no expiration.
+// File is used to save the cache on shutdown and load it on start.
+type CacheConfig struct {
+	Size uint   `json:"size" yaml:"size" toml:"size"`
+	TTL  uint   `json:"ttl" yaml:"ttl" toml:"ttl"`
+	File string `json:"file" yaml:"file" toml:"file"`
+}
+
+// LimitsConfig is requests limits.
+// Rate is a number of POST:/event requests per minute, zero value means no limits.
+// Body is maximum request body size (bytes), zero value means default 64KB.
+// Text and Lookup are maximum text lengths (characters) for translation
+// and dictionary lookup, zero values mean provider's limit and 100.
+// Commands is maximum number of commands (lines and target languages)
+// in a chat message, zero value means 5.
+// UsageFile keeps upstream usage counters (sent characters by provider) between restarts.
+type LimitsConfig struct {
+	Rate      uint   `json:"rate" yaml:"rate" toml:"rate"`
+	Burst     uint   `json:"burst" yaml:"burst" toml:"burst"`
+	Body      uint   `json:"body" yaml:"body" toml:"body"`
+	Text      uint   `json:"text" yaml:"text" toml:"text"`
+	Lookup    uint   `json:"lookup" yaml:"lookup" toml:"lookup"`
+	Commands  uint   `json:"commands" yaml:"commands" toml:"commands"`
+	UsageFile string `json:"usage_file" yaml:"usage_file" toml:"usage_file"`
+}
+
+// LoggingConfig is logging settings.
+// Empty file means standard output streams, Quiet disables info messages.
+type LoggingConfig struct {
+	File  string `json:"file" yaml:"file" toml:"file"`
+	Quiet bool   `json:"quiet" yaml:"quiet" toml:"quiet"`
+}
+
+// Config is service settings.
+// TranslationKey and DictionaryKey are shortcuts of "yandex" provider keys
+// for backward compatibility with flat configuration files.
+// Shutdown is a period (seconds) to drain in-flight requests on shutdown,
+// Refresh is a period (seconds) of languages reload, zero value disables it.
+type Config struct {
+	Host           string                     `json:"host" yaml:"host" toml:"host"`
+	Port           uint                       `json:"port" yaml:"port" toml:"port"`
+	TranslationKey string                     `json:"tkey" yaml:"tkey" toml:"tkey"`
+	DictionaryKey  string                     `json:"dkey" yaml:"dkey" toml:"dkey"`
+	TimeoutValue   uint                       `json:"timeout" yaml:"timeout" toml:"timeout"`
+	Shutdown       uint                       `json:"shutdown" yaml:"shutdown" toml:"shutdown"`
+	Refresh        uint                       `json:"refresh" yaml:"refresh" toml:"refresh"`
+	Providers      map[string]*ProviderConfig `json:"providers" yaml:"providers" toml:"providers"`
+	Routing        RoutingConfig              `json:"routing" yaml:"routing" toml:"routing"`
+	Cache          CacheConfig                `json:"cache" yaml:"cache" toml:"cache"`
+	History        HistoryConfig              `json:"history" yaml:"history" toml:"history"`
+	Auto           AutoConfig                 `json:"auto" yaml:"auto" toml:"auto"`
+	Prefs          PrefsConfig                `json:"prefs" yaml:"prefs" toml:"prefs"`
+	Translit       TranslitConfig             `json:"translit" yaml:"translit" toml:"translit"`
+	Layout         LayoutConfig               `json:"layout" yaml:"layout" toml:"layout"`
+	Glossary       GlossaryConfig             `json:"glossary" yaml:"glossary" toml:"glossary"`
+	Limits         LimitsConfig               `json:"limits" yaml:"limits" toml:"limits"`
+	Logging        LoggingConfig              `json:"logging" yaml:"logging" toml:"logging"`
+	TLS            TLSConfig                  `json:"tls" yaml:"tls" toml:"tls"`
+	Auth           AuthConfig                 `json:"auth" yaml:"auth" toml:"auth"`
+	Transport      TransportConfig            `json:"transport" yaml:"transport" toml:"transport"`
+	timeout        time.Duration
+	problems       []string
+}
+
+// Addr returns service's net address.
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, fmt.Sprint(c.Port))
+}
+
+// Timeout returns upstream requests timeout.
+func (c *Config) Timeout() time.Duration {
+	return c.timeout
+}
+
+// ShutdownTimeout returns a period to drain in-flight requests on shutdown.
+func (c *Config) ShutdownTimeout() time.Duration {
+	if c.Shutdown == 0 {
+		return defaultShutdown
+	}
+	return time.Duration(c.Shutdown) * time.Second
+}
+
+// provider returns API URLs of the provider.
+func (p *ProviderConfig) provider() *translator.Provider {
+	trURL, dictURL := p.TranslateURL, p.DictionaryURL
+	if trURL == "" {
+		trURL = translator.YandexTranslateURL
+	}
+	if dictURL == "" {
+		dictURL = translator.YandexDictionaryURL
+	}
+	provider := translator.NewYandex(trURL, dictURL)
+	// the encoding is checked by Validate
+	provider.Encoding, _ = translator.ParseEncoding(p.Encoding)
+	provider.Auth = translator.Auth{Param: p.KeyParam, Header: p.AuthHeader, Prefix: p.AuthPrefix}
+	return provider
+}
+
+// normalize sets default values. Flat keys fill empty keys of "yandex" provider.
+func (c *Config) normalize() {
+	if c.Providers == nil {
+		c.Providers = make(map[string]*ProviderConfig)
+	}
+	p, ok := c.Providers[providerYandex]
+	if !ok && (len(c.Providers) == 0 || c.TranslationKey != "" || c.DictionaryKey != "") {
+		p = &ProviderConfig{}
+		c.Providers[providerYandex] = p
+	}
+	if p != nil {
+		if p.TranslationKey == "" {
+			p.TranslationKey = c.TranslationKey
+		}
+		if p.DictionaryKey == "" {
+			p.DictionaryKey = c.DictionaryKey
+		}
+	}
+	for _, p := range c.Providers {
+		if p != nil && p.Type == "" {
+			p.Type = providerYandex
+		}
+	}
+	if c.Routing.Translate == "" {
+		c.Routing.Translate = providerYandex
+	}
+	if c.Routing.Dictionary == "" {
+		c.Routing.Dictionary = providerYandex
+	}
+	if c.Limits.Rate > 0 && c.Limits.Burst == 0 {
+		c.Limits.Burst = 1
+	}
+	if c.TimeoutValue != 0 {
+		c.timeout = time.Duration(c.TimeoutValue) * time.Second
+	} else {
+		c.timeout = defaultTimeout
+	}
+}
+
+// ValidationError is a list of all found configuration problems.
+type ValidationError []string
+
+// Error is an implementation of error interface.
+func (e ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e, "; ")
+}
+
+// Validate checks that configuration can be used.
+// It returns ValidationError with all found problems.
+func (c *Config) Validate() error {
+	// unknown fields of the configuration file are reported with other problems
+	problems := append(ValidationError(nil), c.problems...)
+	if c.Port == 0 || c.Port > maxPort {
+		problems = append(problems, fmt.Sprintf("port: %v is out of range [1, %v]", c.Port, maxPort))
+	}
+	if c.TimeoutValue > maxTimeout {
+		problems = append(problems, fmt.Sprintf(
+			"timeout: %v seconds is too big, maximum is %v, 0 - default %v", c.TimeoutValue, maxTimeout, defaultTimeout))
+	}
+	names := make([]string, 0, len(c.Providers))
+	for name := range c.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := c.Providers[name]
+		if p == nil {
+			problems = append(problems, fmt.Sprintf("providers.%v: empty provider settings", name))
+			continue
+		}
+		if p.Type != providerYandex {
+			problems = append(problems, fmt.Sprintf(
+				"providers.%v.type: unknown provider type %q, supported: %q", name, p.Type, providerYandex))
+		}
+		if _, err := translator.ParseEncoding(p.Encoding); err != nil {
+			problems = append(problems, fmt.Sprintf(
+				"providers.%v.encoding: %v, supported: \"form\", \"query\", \"json\"", name, err))
+		}
+		if p.AuthHeader != "" && p.KeyParam != "" {
+			problems = append(problems, fmt.Sprintf(
+				"providers.%v.key_param: can't be used with auth_header, remove one of them", name))
+		}
+	}
+	if p, ok := c.Providers[providerYandex]; ok && p != nil {
+		if c.TranslationKey != "" && c.TranslationKey != p.TranslationKey {
+			problems = append(problems, "tkey: conflicts with providers.yandex.tkey, remove one of them")
+		}
+		if c.DictionaryKey != "" && c.DictionaryKey != p.DictionaryKey {
+			problems = append(problems, "dkey: conflicts with providers.yandex.dkey, remove one of them")
+		}
+	}
+	problems = append(problems, c.TLS.validate()...)
+	problems = append(problems, c.Auth.validate()...)
+	problems = append(problems, c.Limits.validate()...)
+	problems = append(problems, c.Transport.validate()...)
+	problems = append(problems, c.Glossary.validate()...)
+	problems = append(problems, c.validateRoute("translate", c.Routing.Translate, "tkey")...)
+	problems = append(problems, c.validateRoute("dictionary", c.Routing.Dictionary, "dkey")...)
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateRoute checks that routed provider exists and has required API key.
+func (c *Config) validateRoute(mode, name, key string) []string {
+	p, ok := c.Providers[name]
+	if !ok {
+		return []string{fmt.Sprintf("routing.%v: unknown provider %q, add it to \"providers\" section", mode, name)}
+	}
+	if p == nil {
+		return nil
+	}
+	value := p.TranslationKey
+	if key == "dkey" {
+		value = p.DictionaryKey
+	}
+	if value != "" {
+		return nil
+	}
+	fullKey := fmt.Sprintf("providers_%v_%v", name, key)
+	msg := fmt.Sprintf("providers.%v.%v: %v API key is required, set it in the file or %v",
+		name, key, mode, EnvName(fullKey))
+	if name == providerYandex {
+		msg += fmt.Sprintf(", or use %q, %v, -%v", key, EnvName(key), key)
+	}
+	return []string{msg}
+}
+
+// decodeConfig decodes configuration data to v by file extension: YAML, TOML or JSON.
+// Unknown fields are not decoded, they are returned sorted with their full names like "limits.rate".
+func decodeConfig(file string, data []byte, v interface{}) ([]string, error) {
+	var (
+		raw interface{}
+		tag string
+	)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, v); err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		tag = "yaml"
+	case ".toml":
+		if _, err := toml.Decode(string(data), v); err != nil {
+			return nil, err
+		}
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, err
+		}
+		tag = "toml"
+	default:
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		tag = "json"
+	}
+	unknown := unknownFields(raw, reflect.TypeOf(v), tag, "")
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// unknownFields returns names of data fields which are absent in the type t.
+// Struct fields are matched by tag names, JSON and TOML names are case-insensitive as their decoders do.
+func unknownFields(data interface{}, t reflect.Type, tag, prefix string) []string {
+	var unknown []string
+	values, ok := data.(map[string]interface{})
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !ok {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		for key, value := range values {
+			unknown = append(unknown, unknownFields(value, t.Elem(), tag, prefix+key+".")...)
+		}
+	case reflect.Struct:
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				fields[fieldKey(name, tag)] = t.Field(i).Type
+			}
+		}
+		for key, value := range values {
+			fieldType, ok := fields[fieldKey(key, tag)]
+			if !ok {
+				unknown = append(unknown, prefix+key)
+				continue
+			}
+			unknown = append(unknown, unknownFields(value, fieldType, tag, prefix+key+".")...)
+		}
+	}
+	return unknown
+}
+
+// fieldKey returns a key to match field name of the format.
+func fieldKey(name, tag string) string {
+	if tag == "yaml" {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// ReadConfig reads configuration file and applies overrides.
+// The file format is chosen by its extension: ".yaml", ".yml", ".toml" or JSON for others.
+// Values precedence from lowest to highest:
+// defaults, configuration file, environment variables (TRBOT_TKEY, TRBOT_TKEY_FILE...),
+// command-line flags (overrides). Empty file name means no configuration file.
+// Unknown fields of the file are reported by Validate.
+func ReadConfig(file string, overrides Overrides) (*Config, error) {
+	cfg := &Config{}
+	if file != "" {
+		_, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		unknown, err := decodeConfig(file, data, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("file %v: %v", file, err)
+		}
+		for _, name := range unknown {
+			cfg.problems = append(cfg.problems, fmt.Sprintf("%v: unknown field", name))
+		}
+	}
+	if err := cfg.override(envLookup); err != nil {
+		return nil, fmt.Errorf("environment variable %v", err)
+	}
+	if err := cfg.override(overrides.lookup); err != nil {
+		return nil, fmt.Errorf("flag %v", err)
+	}
+	cfg.normalize()
+	return cfg, nil
+}
@@ -0,0 +1,264 @@
+package bot
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+	"github.com/z0rr0/transalation-bot/translator/providertest"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	return file
+}
+
+func TestReadConfig(t *testing.T) {
+	file := writeTestFile(t, "config.json",
+		`{"host": "localhost", "port": 8080, "tkey": "file", "dkey": "file", "timeout": 5}`)
+	secret := writeTestFile(t, "dkey", "secret\n")
+
+	t.Setenv("TRBOT_PORT", "9090")
+	t.Setenv("TRBOT_TKEY", "env")
+	t.Setenv("TRBOT_DKEY_FILE", secret)
+
+	overrides := Overrides{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides.Flags(fs)
+	if err := fs.Parse([]string{"-timeout", "7", "-tkey", "flag"}); err != nil {
+		t.Fatalf("flags parse error: %v", err)
+	}
+	cfg, err := ReadConfig(file, overrides)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := cfg.Addr(); a != "localhost:9090" {
+		t.Errorf("wrong address: %v", a)
+	}
+	if cfg.TranslationKey != "flag" {
+		t.Errorf("wrong translation key: %v", cfg.TranslationKey)
+	}
+	if cfg.DictionaryKey != "secret" {
+		t.Errorf("wrong dictionary key: %v", cfg.DictionaryKey)
+	}
+	if d := cfg.Timeout(); d != 7*time.Second {
+		t.Errorf("wrong timeout: %v", d)
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	t.Setenv("TRBOT_PORT", "bad")
+	if _, err := ReadConfig("", Overrides{}); err == nil {
+		t.Error("expected error for wrong port value")
+	}
+	t.Setenv("TRBOT_PORT", "8080")
+	if _, err := ReadConfig("", Overrides{"tkey_file": "/not/found"}); err == nil {
+		t.Error("expected error for unknown file")
+	}
+	if _, err := ReadConfig("/not/found/config.json", Overrides{}); err == nil {
+		t.Error("expected error for unknown configuration file")
+	}
+	cfg, err := ReadConfig("", Overrides{"tkey": "a", "dkey": "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.Timeout() != defaultTimeout {
+		t.Errorf("wrong configuration: %+v", cfg)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{Port: 8080, TranslationKey: "a", DictionaryKey: "b", TimeoutValue: 5}
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	cfg = &Config{Port: 70000, TimeoutValue: 1000}
+	cfg.normalize()
+	err := cfg.Validate()
+	problems, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+	if n := len(problems); n != 4 {
+		t.Errorf("wrong number of problems %v: %v", n, problems)
+	}
+}
+
+func TestReadConfigUnknownFields(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{"port": 0, "tkey": "", "dkey": "b", "bogus": 1, "limits": {"rate": 1, "extra": 2},
+			"providers": {"yandex": {"tkye": "c"}}}`,
+		"config.yaml": "port: 0\ntkey: \"\"\ndkey: b\nbogus: 1\nlimits:\n  rate: 1\n  extra: 2\n" +
+			"providers:\n  yandex:\n    tkye: c\n",
+		"config.toml": "port = 0\ntkey = \"\"\ndkey = \"b\"\nbogus = 1\n[limits]\nrate = 1\nextra = 2\n" +
+			"[providers.yandex]\ntkye = \"c\"\n",
+	}
+	expected := []string{
+		"bogus: unknown field",
+		"limits.extra: unknown field",
+		"providers.yandex.tkye: unknown field",
+		"port: 0 is out of range",
+		"providers.yandex.tkey: translate API key is required",
+	}
+	for name, content := range files {
+		cfg, err := ReadConfig(writeTestFile(t, name, content), Overrides{})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+		err = cfg.Validate()
+		if err == nil {
+			t.Fatalf("%v: expected error for unknown fields", name)
+		}
+		for _, problem := range expected {
+			if !strings.Contains(err.Error(), problem) {
+				t.Errorf("%v: problem %q is not found in %v", name, problem, err)
+			}
+		}
+	}
+}
+
+func TestValidateProviders(t *testing.T) {
+	cfg := &Config{
+		Port:           8080,
+		TranslationKey: "flat",
+		Providers: map[string]*ProviderConfig{
+			"yandex": {TranslationKey: "nested", DictionaryKey: "b"},
+			"other":  {Type: "unknown", Encoding: "xml", KeyParam: "api_key", AuthHeader: "X-Key"},
+		},
+		Routing: RoutingConfig{Dictionary: "missing"},
+	}
+	cfg.normalize()
+	problems, ok := cfg.Validate().(ValidationError)
+	if !ok {
+		t.Fatal("expected validation error")
+	}
+	// unknown type and encoding, key_param with auth_header, keys conflict, unknown routed provider
+	if n := len(problems); n != 5 {
+		t.Errorf("wrong number of problems %v: %v", n, problems)
+	}
+}
+
+func TestProviderContract(t *testing.T) {
+	providers := map[string]ProviderConfig{
+		"default": {},
+		"query":   {Encoding: "query", KeyParam: "api_key"},
+		"json":    {Encoding: "json", AuthHeader: "Authorization", AuthPrefix: "Api-Key "},
+	}
+	for name, p := range providers {
+		p := p
+		t.Run(name, func(t *testing.T) {
+			providertest.Run(t, func(baseURL string) *translator.Provider {
+				p.TranslateURL, p.DictionaryURL = baseURL+"/tr.json", baseURL+"/dicservice.json"
+				return p.provider()
+			})
+		})
+	}
+}
+
+func TestReadConfigFormats(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+port: 8080
+providers:
+  yandex:
+    tkey: a
+    dkey: b
+  mirror:
+    tkey: c
+    dkey: d
+    translate_url: http://localhost/tr.json
+routing:
+  translate: mirror
+cache:
+  size: 100
+  ttl: 60
+limits:
+  rate: 30
+logging:
+  quiet: true
+`,
+		"config.toml": `
+port = 8080
+
+[providers.yandex]
+tkey = "a"
+dkey = "b"
+
+[providers.mirror]
+tkey = "c"
+dkey = "d"
+translate_url = "http://localhost/tr.json"
+
+[routing]
+translate = "mirror"
+
+[cache]
+size = 100
+ttl = 60
+
+[limits]
+rate = 30
+
+[logging]
+quiet = true
+`,
+	}
+	for name, content := range files {
+		cfg, err := ReadConfig(writeTestFile(t, name, content), Overrides{})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+		if err = cfg.Validate(); err != nil {
+			t.Errorf("%v: unexpected error: %v", name, err)
+		}
+		mirror := cfg.Providers["mirror"]
+		if mirror == nil || mirror.TranslationKey != "c" || mirror.Type != providerYandex {
+			t.Errorf("%v: wrong provider: %+v", name, mirror)
+		}
+		if cfg.Routing.Translate != "mirror" || cfg.Routing.Dictionary != providerYandex {
+			t.Errorf("%v: wrong routing: %+v", name, cfg.Routing)
+		}
+		if cfg.Cache.Size != 100 || cfg.Limits.Rate != 30 || cfg.Limits.Burst != 1 || !cfg.Logging.Quiet {
+			t.Errorf("%v: wrong settings: %+v", name, cfg)
+		}
+		cfg, err = ReadConfig(writeTestFile(t, name, content+"\nunknown = 1\n"), Overrides{})
+		if err == nil {
+			err = cfg.Validate()
+		}
+		if err == nil {
+			t.Errorf("%v: expected error for unknown field", name)
+		}
+	}
+}
+
+func TestReadConfigFlat(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"port": 8080, "tkey": "a", "dkey": "b", "timeout": 5}`)
+	cfg, err := ReadConfig(file, Overrides{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	p := cfg.Providers[providerYandex]
+	if p == nil || p.TranslationKey != "a" || p.DictionaryKey != "b" {
+		t.Errorf("wrong yandex provider: %+v", p)
+	}
+	file = writeTestFile(t, "nested.json", `{"port": 8080, "providers": {"yandex": {"tkey": "a", "dkey": "b"}}}`)
+	t.Setenv("TRBOT_PROVIDERS_YANDEX_TKEY", "env")
+	cfg, err = ReadConfig(file, Overrides{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p = cfg.Providers[providerYandex]; p.TranslationKey != "env" {
+		t.Errorf("provider key is not overridden: %v", p.TranslationKey)
+	}
+}
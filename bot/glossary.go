@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+// defaultGlossaryReload is default period to check glossary files changes.
+const defaultGlossaryReload = time.Minute
+
+// GlossaryConfig is custom terms settings.
+// Files are glossary files by directions like "en-ru", a file format is chosen
+// by its extension as for the configuration file.
+// Reload is a period (seconds) to check files changes.
+type GlossaryConfig struct {
+	Files  map[string]string `json:"files" yaml:"files" toml:"files"`
+	Reload uint              `json:"reload" yaml:"reload" toml:"reload"`
+}
+
+// validate returns glossary settings problems.
+func (c *GlossaryConfig) validate() []string {
+	var problems []string
+	directions := make([]string, 0, len(c.Files))
+	for direction := range c.Files {
+		directions = append(directions, direction)
+	}
+	sort.Strings(directions)
+	for _, direction := range directions {
+		if translator.LangDirect.FindString(direction) != direction {
+			problems = append(problems, fmt.Sprintf("glossary.files.%v: invalid direction, expected like \"en-ru\"", direction))
+			continue
+		}
+		if _, err := os.Stat(c.Files[direction]); err != nil {
+			problems = append(problems, fmt.Sprintf("glossary.files.%v: %v", direction, err))
+		}
+	}
+	return problems
+}
+
+// loadGlossary reads a glossary file and returns it with file modification time.
+func loadGlossary(file string) (*translator.Glossary, time.Time, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	g := &translator.Glossary{}
+	unknown, err := decodeConfig(file, data, g)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("glossary %v: %v", file, err)
+	}
+	if len(unknown) > 0 {
+		return nil, time.Time{}, fmt.Errorf("glossary %v: unknown fields %v", file, unknown)
+	}
+	if err = g.Prepare(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("glossary %v: %v", file, err)
+	}
+	return g, info.ModTime(), nil
+}
+
+// glossaries keeps glossaries of directions up to date with their files.
+type glossaries struct {
+	sync.RWMutex
+	files    map[string]string
+	items    map[string]*translator.Glossary
+	modTimes map[string]time.Time
+}
+
+// newGlossaries loads glossary files, it returns nil if there are no files.
+func newGlossaries(cfg GlossaryConfig) (*glossaries, error) {
+	if len(cfg.Files) == 0 {
+		return nil, nil
+	}
+	g := &glossaries{
+		files:    cfg.Files,
+		items:    make(map[string]*translator.Glossary, len(cfg.Files)),
+		modTimes: make(map[string]time.Time, len(cfg.Files)),
+	}
+	for direction, file := range cfg.Files {
+		item, modTime, err := loadGlossary(file)
+		if err != nil {
+			return nil, err
+		}
+		g.items[direction], g.modTimes[direction] = item, modTime
+	}
+	return g, nil
+}
+
+// get returns a glossary of the direction, it's nil if there is no glossary.
+func (g *glossaries) get(direction string) *translator.Glossary {
+	if g == nil {
+		return nil
+	}
+	g.RLock()
+	defer g.RUnlock()
+	return g.items[direction]
+}
+
+// reload reads changed glossary files, failed reloads are logged and previous glossaries are kept.
+func (g *glossaries) reload(logger *log.Logger) {
+	if g == nil {
+		return
+	}
+	for direction, file := range g.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			logger.Printf("glossary check error: %v", err)
+			continue
+		}
+		g.RLock()
+		changed := info.ModTime().After(g.modTimes[direction])
+		g.RUnlock()
+		if !changed {
+			continue
+		}
+		item, modTime, err := loadGlossary(file)
+		if err != nil {
+			logger.Printf("glossary reload error, old one is kept: %v", err)
+			continue
+		}
+		g.Lock()
+		g.items[direction], g.modTimes[direction] = item, modTime
+		g.Unlock()
+	}
+}
+
+// sameGlossaries returns true if glossary files of configurations are equal.
+func sameGlossaries(a, b *GlossaryConfig) bool {
+	return reflect.DeepEqual(a.Files, b.Files)
+}
+
+// WatchGlossary reloads changed glossary files periodically, it stops when ctx is done.
+func (s *Service) WatchGlossary(ctx context.Context) {
+	for {
+		period := time.Duration(s.current().cfg.Glossary.Reload) * time.Second
+		if period == 0 {
+			period = defaultGlossaryReload
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(period):
+			s.current().glossary.reload(s.loggerError)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+func TestGlossary(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	file := filepath.Join(t.TempDir(), "en-ru.yaml")
+	data := "terms:\n  Radio-T: \"\"\n  umputun: Умпутун\ndictionary:\n  podcast: [подкаст, передача]\n"
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := *s.current().cfg
+	cfg.Glossary = GlossaryConfig{Files: map[string]string{"en-ru": file}}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	article := "podcast" + translator.Separator + "подкаст" + translator.Separator + "  передача"
+	testValues := map[string]string{
+		"en-ru listen Radio-T with umputun": "[listen Radio-T with Умпутун]",
+		"en-ru podcast":                     article,
+		"en-ru -t podcast":                  "[podcast]",
+		"ru-en -t Radio-T":                  "[Radio-T]",
+	}
+	for k, v := range testValues {
+		result, err := s.Translate(ctx, k)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+	batch, err := s.TranslateBatch(ctx, "en-ru", []string{"umputun says", "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := strings.Join(batch, "|"); r != "[Умпутун says]|[hello]" {
+		t.Errorf("wrong batch result: %q", r)
+	}
+	dict, err := s.Lookup(ctx, "en-ru", "Podcast")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dict.Def) != 1 || len(dict.Def[0].Tr) != 2 {
+		t.Errorf("wrong glossary article: %v", dict)
+	}
+	// hot reload, cached translation gets a new term value
+	data = "terms:\n  umputun: Бобук\n"
+	if err := ioutil.WriteFile(file, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	s.current().glossary.reload(log.New(ioutil.Discard, "", 0))
+	if result, err := s.Translate(ctx, "en-ru listen Radio-T with umputun"); err != nil || result != "[listen Radio-T with Бобук]" {
+		t.Errorf("wrong result after reload: %q, %v", result, err)
+	}
+	// invalid glossary is not applied
+	if err := ioutil.WriteFile(file, []byte("terms: ["), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime = modTime.Add(time.Minute)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	s.current().glossary.reload(log.New(ioutil.Discard, "", 0))
+	if result, err := s.Translate(ctx, "en-ru -t umputun"); err != nil || result != "[Бобук]" {
+		t.Errorf("wrong result after failed reload: %q, %v", result, err)
+	}
+}
+
+func TestGlossaryValidate(t *testing.T) {
+	cfg := GlossaryConfig{Files: map[string]string{
+		"en-ru":   filepath.Join(t.TempDir(), "missing.yaml"),
+		"english": "english.yaml",
+	}}
+	problems := cfg.validate()
+	if len(problems) != 2 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if !strings.HasPrefix(problems[0], "glossary.files.en-ru:") || !strings.HasPrefix(problems[1], "glossary.files.english:") {
+		t.Errorf("wrong problems: %v", problems)
+	}
+}
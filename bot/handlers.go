@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"unicode/utf8"
+)
+
+// InfoResponse is http GET:/info JSON response.
+type InfoResponse struct {
+	Author   string   `json:"author"`
+	Info     string   `json:"info"`
+	Commands []string `json:"commands"`
+}
+
+// EventRequest is http POST:/event request.
+type EventRequest struct {
+	Text        string `json:"text"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Channel     string `json:"channel"`
+}
+
+// EventResponse is http POSt:/event response.
+type EventResponse struct {
+	Text string `json:"text"`
+	Bot  string `json:"bot"`
+}
+
+// BatchRequest is http POST:/translate/batch request.
+type BatchRequest struct {
+	Direction string   `json:"direction"`
+	Texts     []string `json:"texts"`
+}
+
+// BatchResponse is http POST:/translate/batch response,
+// translations have the same order as request's texts.
+type BatchResponse struct {
+	Direction string   `json:"direction"`
+	Texts     []string `json:"texts"`
+	Bot       string   `json:"bot"`
+}
+
+// httpError is an error with HTTP response status code.
+type httpError struct {
+	code int
+	msg  string
+}
+
+// Error is an implementation of error interface.
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+// handlerFunc is HTTP handler which returns an error for failed requests.
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// replyError writes error response, its status code is
+// http.StatusExpectationFailed if err is not *httpError.
+// REST API errors are JSON and their default code is http.StatusBadGateway.
+func replyError(w http.ResponseWriter, r *http.Request, err error) {
+	isAPI := isAPIRequest(r)
+	code := http.StatusExpectationFailed
+	if isAPI {
+		code = http.StatusBadGateway
+	}
+	if e, ok := err.(*httpError); ok {
+		code = e.code
+	}
+	if isAPI {
+		writeAPIError(w, code, err.Error())
+		return
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// handle converts h to http.Handler which replies errors.
+func (s *Service) handle(h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			replyError(w, r, err)
+		}
+	})
+}
+
+// writeJSON writes JSON response with the status code.
+func (s *Service) writeJSON(w http.ResponseWriter, code int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(response); err != nil {
+		s.loggerError.Printf("failed json encode: %v", err)
+	}
+}
+
+// decodeEvent decodes POST:/event request body.
+func decodeEvent(r *http.Request) (*EventRequest, error) {
+	req := &EventRequest{}
+	if err := decodeRequest(r, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// decodeRequest decodes JSON request body to req.
+// The body is checked before decoding, because JSON decoder replaces invalid UTF-8 bytes.
+func decodeRequest(r *http.Request, req interface{}) error {
+	var maxErr *http.MaxBytesError
+	data, err := ioutil.ReadAll(r.Body)
+	switch {
+	case errors.As(err, &maxErr):
+		return &httpError{
+			code: http.StatusRequestEntityTooLarge,
+			msg:  fmt.Sprintf("request is too large, maximum is %v bytes", maxErr.Limit),
+		}
+	case err != nil:
+		return &httpError{code: http.StatusBadRequest, msg: fmt.Sprintf("failed request read: %v", err)}
+	case len(bytes.TrimSpace(data)) == 0:
+		return &httpError{code: http.StatusBadRequest, msg: "empty request"}
+	case !utf8.Valid(data):
+		return &httpError{code: http.StatusBadRequest, msg: "text is not valid UTF-8"}
+	}
+	if err = json.Unmarshal(data, req); err != nil {
+		return &httpError{code: http.StatusBadRequest, msg: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return nil
+}
+
+// handlerInfo is handler for GET:/info request.
+func (s *Service) handlerInfo(w http.ResponseWriter, r *http.Request) error {
+	response := &InfoResponse{
+		Author:   Author,
+		Info:     "Radio-t chat yandex translation-bot",
+		Commands: []string{},
+	}
+	s.writeJSON(w, http.StatusCreated, response)
+	return nil
+}
+
+// handlerEvent is handler for POST:/event request.
+func (s *Service) handlerEvent(w http.ResponseWriter, r *http.Request) error {
+	req, err := decodeEvent(r)
+	if err != nil {
+		s.loggerError.Printf("JSON decode eror: %v", err)
+		return err
+	}
+	text, err := cleanText(req.Text)
+	if err != nil {
+		return err
+	}
+	req.Text = text
+	result, err := s.HandleEvent(r.Context(), req)
+	if err != nil {
+		s.loggerError.Printf("translation eror: %v", err)
+		return err
+	}
+	if result == "" {
+		return errors.New("nothing")
+	}
+	response := &EventResponse{
+		Text: result,
+		Bot:  Name,
+	}
+	s.writeJSON(w, http.StatusCreated, response)
+	return nil
+}
+
+// handlerBatch is handler for POST:/translate/batch request.
+func (s *Service) handlerBatch(w http.ResponseWriter, r *http.Request) error {
+	req := &BatchRequest{}
+	if err := decodeRequest(r, req); err != nil {
+		s.loggerError.Printf("JSON decode eror: %v", err)
+		return err
+	}
+	if len(req.Texts) == 0 {
+		return &httpError{code: http.StatusBadRequest, msg: "empty texts"}
+	}
+	texts := make([]string, len(req.Texts))
+	for i, text := range req.Texts {
+		text, err := cleanText(text)
+		if err != nil {
+			return err
+		}
+		texts[i] = text
+	}
+	result, err := s.TranslateBatch(r.Context(), req.Direction, texts)
+	if err != nil {
+		s.loggerError.Printf("batch translation eror: %v", err)
+		return err
+	}
+	response := &BatchResponse{
+		Direction: req.Direction,
+		Texts:     result,
+		Bot:       Name,
+	}
+	s.writeJSON(w, http.StatusOK, response)
+	return nil
+}
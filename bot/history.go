@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"regexp"
+	"sync"
+	"time"
+)
+
+// defaultHistoryChannels is default maximum number of channels in history
+const defaultHistoryChannels = 100
+
+// historyCommand is a pattern of a command which translates previous message,
+// like "en-ru ^" or "ru ^username", a single language is a target one.
+// The direction can be omitted only if the user has default one, otherwise the message is not a command.
+var historyCommand = regexp.MustCompile(`^\s*(?:([a-z]{2,3}(?:-[a-z]{2,3})?)\s+)?\^(\S*)\s*$`)
+
+// HistoryConfig is chat messages history settings.
+// Size is a number of kept messages per channel, zero value disables the history.
+// TTL is messages retention (seconds), zero value means no expiration.
+// Channels is maximum number of channels, zero value means 100.
+type HistoryConfig struct {
+	Size     uint `json:"size" yaml:"size" toml:"size"`
+	TTL      uint `json:"ttl" yaml:"ttl" toml:"ttl"`
+	Channels uint `json:"channels" yaml:"channels" toml:"channels"`
+}
+
+// historyItem is a chat message.
+type historyItem struct {
+	username string
+	text     string
+	added    time.Time
+}
+
+// history keeps recent messages of chat channels.
+type history struct {
+	sync.Mutex
+	size        int
+	ttl         time.Duration
+	maxChannels int
+	channels    map[string][]historyItem
+}
+
+// newHistory returns new messages history, it is nil if the history is disabled.
+func newHistory(cfg HistoryConfig) *history {
+	if cfg.Size == 0 {
+		return nil
+	}
+	maxChannels := int(cfg.Channels)
+	if maxChannels == 0 {
+		maxChannels = defaultHistoryChannels
+	}
+	return &history{
+		size:        int(cfg.Size),
+		ttl:         time.Duration(cfg.TTL) * time.Second,
+		maxChannels: maxChannels,
+		channels:    make(map[string][]historyItem),
+	}
+}
+
+// add saves a message of the channel, the oldest messages
+// and the least recently active channel are removed over the limits.
+func (h *history) add(channel, username, text string) {
+	if h == nil {
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+	items, ok := h.channels[channel]
+	if !ok && len(h.channels) >= h.maxChannels {
+		h.removeOldestChannel()
+	}
+	items = append(items, historyItem{username: username, text: text, added: time.Now()})
+	if n := len(items); n > h.size {
+		items = append([]historyItem(nil), items[n-h.size:]...)
+	}
+	h.channels[channel] = items
+}
+
+// removeOldestChannel removes a channel with the oldest last message.
+func (h *history) removeOldestChannel() {
+	var (
+		oldest string
+		added  time.Time
+	)
+	for channel, items := range h.channels {
+		last := items[len(items)-1].added
+		if added.IsZero() || last.Before(added) {
+			oldest, added = channel, last
+		}
+	}
+	delete(h.channels, oldest)
+}
+
+// last returns not expired the most recent message of the channel,
+// if username is not empty, it's the last message of this user.
+func (h *history) last(channel, username string) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	h.Lock()
+	defer h.Unlock()
+	items := h.channels[channel]
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if h.ttl > 0 && time.Since(item.added) > h.ttl {
+			// older messages are expired too
+			if i == len(items)-1 {
+				delete(h.channels, channel)
+			} else {
+				h.channels[channel] = items[i+1:]
+			}
+			break
+		}
+		if username == "" || item.username == username {
+			return item.text, true
+		}
+	}
+	return "", false
+}
+
+// parseHistoryCommand returns direction and username of a command like "en-ru ^username".
+func parseHistoryCommand(text string) (string, string, bool) {
+	found := historyCommand.FindStringSubmatch(text)
+	if found == nil {
+		return "", "", false
+	}
+	return found[1], found[2], true
+}
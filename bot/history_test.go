@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+	if h := newHistory(HistoryConfig{}); h != nil {
+		t.Error("disabled history is not nil")
+	}
+	h := newHistory(HistoryConfig{Size: 2, Channels: 2})
+	h.add("a", "alice", "one")
+	h.add("a", "bob", "two")
+	h.add("a", "bob", "three")
+	if text, ok := h.last("a", ""); !ok || text != "three" {
+		t.Errorf("wrong last message: %v", text)
+	}
+	if text, ok := h.last("a", "alice"); ok {
+		t.Errorf("old message is not removed: %v", text)
+	}
+	h.add("b", "alice", "four")
+	h.add("c", "alice", "five")
+	if _, ok := h.last("a", ""); ok {
+		t.Error("the least recently active channel is not removed")
+	}
+	if text, ok := h.last("b", "alice"); !ok || text != "four" {
+		t.Errorf("wrong user's message: %v", text)
+	}
+	h.ttl = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, ok := h.last("c", ""); ok {
+		t.Error("expired message is found")
+	}
+	h.add("d", "alice", "six")
+	if n := len(h.channels); n != 2 {
+		t.Errorf("wrong number of channels: %v", n)
+	}
+}
+
+func TestParseHistoryCommand(t *testing.T) {
+	t.Parallel()
+	testValues := map[string][]string{
+		"en-ru ^":        {"en-ru", ""},
+		" ru ^username ": {"ru", "username"},
+		"en-ru ^ text":   nil,
+		"en-ru text":     nil,
+		"^username":      {"", "username"},
+	}
+	for k, v := range testValues {
+		direction, username, ok := parseHistoryCommand(k)
+		if ok != (v != nil) {
+			t.Errorf("wrong result for %q: %v", k, ok)
+			continue
+		}
+		if ok && (direction != v[0] || username != v[1]) {
+			t.Errorf("wrong command for %q: %q, %q", k, direction, username)
+		}
+	}
+}
+
+func TestHistoryCommands(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	cfg := *s.current().cfg
+	cfg.History = HistoryConfig{Size: 10}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	events := []struct {
+		event *EventRequest
+		want  string
+	}{
+		{&EventRequest{Channel: "main", Username: "alice", Text: "hello big world"}, ""},
+		{&EventRequest{Channel: "main", Username: "bob", Text: "привет"}, ""},
+		{&EventRequest{Channel: "other", Username: "carol", Text: "bye"}, ""},
+		{&EventRequest{Channel: "main", Username: "carol", Text: "ru-en ^"}, "привет(noun)\n[привет] (noun)"},
+		{&EventRequest{Channel: "main", Username: "carol", Text: "ru ^alice"}, "[hello big world]"},
+		{&EventRequest{Channel: "main", Username: "carol", Text: "en-ru -t hi"}, "[hi]"},
+		{&EventRequest{Channel: "main", Username: "carol", Text: "en-ru ^carol"}, "no recent messages of carol"},
+		{&EventRequest{Channel: "main", Username: "carol", Text: "xx ^"}, "unknown target language xx"},
+		{&EventRequest{Channel: "empty", Username: "carol", Text: "en-ru ^"}, "no recent messages"},
+	}
+	for i, e := range events {
+		result, err := s.HandleEvent(ctx, e.event)
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", i, err)
+		}
+		if result != e.want {
+			t.Errorf("wrong result for %v: %q, expected %q", i, result, e.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+const (
+	// defaultLookupLength is default maximum length of dictionary lookup text (runes)
+	defaultLookupLength = 100
+	// maxForcedLookupWords is maximum number of words in dictionary lookup text which is forced
+	// by "-d" flag, mode preference or API request. It's a hard limit, unlike translator.MaxLookupWords
+	// which only selects the dictionary for commands without flags.
+	maxForcedLookupWords = 3
+	// defaultCommands is default maximum number of commands in a message
+	defaultCommands = 5
+)
+
+// cleanText checks that text is valid UTF-8 and removes control characters,
+// new lines and tabs are kept.
+func cleanText(text string) (string, error) {
+	if !utf8.ValidString(text) {
+		return "", &httpError{code: http.StatusBadRequest, msg: "text is not valid UTF-8"}
+	}
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text), nil
+}
+
+// textLimit returns maximum text length (runes) for the translation mode.
+func (c *LimitsConfig) textLimit(isTr bool) int {
+	if isTr {
+		if c.Text == 0 {
+			return translator.MaxTextLength
+		}
+		return int(c.Text)
+	}
+	if c.Lookup == 0 {
+		return defaultLookupLength
+	}
+	return int(c.Lookup)
+}
+
+// commandsLimit returns maximum number of commands in a message.
+func (c *LimitsConfig) commandsLimit() int {
+	if c.Commands == 0 {
+		return defaultCommands
+	}
+	return int(c.Commands)
+}
+
+// checkText checks text length for the translation mode.
+func (c *LimitsConfig) checkText(text string, isTr bool) error {
+	limit := c.textLimit(isTr)
+	if n := utf8.RuneCountInString(text); n > limit {
+		mode := "translation"
+		if !isTr {
+			mode = "dictionary lookup"
+		}
+		return &httpError{
+			code: http.StatusBadRequest,
+			msg:  fmt.Sprintf("text is too long for %v: %v characters, maximum is %v", mode, n, limit),
+		}
+	}
+	if !isTr && len(strings.Fields(text)) > maxForcedLookupWords {
+		return &httpError{
+			code: http.StatusBadRequest,
+			msg:  fmt.Sprintf("dictionary lookup is for a word or a phrase up to %v words", maxForcedLookupWords),
+		}
+	}
+	return nil
+}
+
+// validate returns limits settings problems.
+func (c *LimitsConfig) validate() []string {
+	if c.Text > translator.MaxTextLength {
+		return []string{fmt.Sprintf("limits.text: %v is bigger than provider's limit %v", c.Text, translator.MaxTextLength)}
+	}
+	return nil
+}
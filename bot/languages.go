@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+// LanguageTarget is a target language of a direction.
+type LanguageTarget struct {
+	Code string `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+// LanguageGroup contains translation and dictionary directions of a source language.
+type LanguageGroup struct {
+	Code        string           `json:"code"`
+	Name        string           `json:"name,omitempty"`
+	Translation []LanguageTarget `json:"translation"`
+	Dictionary  []LanguageTarget `json:"dictionary"`
+}
+
+// LanguagesResponse is http GET:/languages response.
+type LanguagesResponse struct {
+	Updated   time.Time       `json:"updated"`
+	Languages []LanguageGroup `json:"languages"`
+}
+
+// langsTag returns ETag value of loaded languages.
+func langsTag(trLangs *translator.LangsListTr, dictLangs *translator.LangsList) string {
+	data, err := json.Marshal([]interface{}{trLangs.Content(), dictLangs.Content(), trLangs.Langs})
+	if err != nil {
+		// it can't be for strings, but ETag must be changed anyway
+		return fmt.Sprintf(`"%x"`, time.Now().UnixNano())
+	}
+	return fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+}
+
+// LanguageGroups returns loaded directions grouped by source language,
+// the groups are sorted by language code.
+func (s *Service) LanguageGroups() *LanguagesResponse {
+	langs := s.Languages()
+	s.mu.RLock()
+	updated := s.langsTime
+	s.mu.RUnlock()
+
+	groups := make(map[string]*LanguageGroup)
+	group := func(code string) *LanguageGroup {
+		g, ok := groups[code]
+		if !ok {
+			g = &LanguageGroup{
+				Code:        code,
+				Name:        langs.Names[code],
+				Translation: []LanguageTarget{},
+				Dictionary:  []LanguageTarget{},
+			}
+			groups[code] = g
+		}
+		return g
+	}
+	for i, directions := range [][]string{langs.Translation, langs.Dictionary} {
+		for _, direction := range directions {
+			pair := strings.SplitN(direction, "-", 2)
+			if len(pair) != 2 {
+				continue
+			}
+			g := group(pair[0])
+			target := LanguageTarget{Code: pair[1], Name: langs.Names[pair[1]]}
+			if i == 0 {
+				g.Translation = append(g.Translation, target)
+			} else {
+				g.Dictionary = append(g.Dictionary, target)
+			}
+		}
+	}
+	response := &LanguagesResponse{Updated: updated, Languages: make([]LanguageGroup, 0, len(groups))}
+	for _, g := range groups {
+		response.Languages = append(response.Languages, *g)
+	}
+	sort.Slice(response.Languages, func(i, j int) bool {
+		return response.Languages[i].Code < response.Languages[j].Code
+	})
+	return response
+}
+
+// writeLanguages writes JSON response with ETag and Last-Modified headers
+// of the last languages refresh, conditional requests get 304 Not Modified.
+func (s *Service) writeLanguages(w http.ResponseWriter, r *http.Request, response interface{}) error {
+	s.mu.RLock()
+	tag, modified := s.langsTag, s.langsTime
+	s.mu.RUnlock()
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if tag != "" {
+		w.Header().Set("ETag", tag)
+	}
+	http.ServeContent(w, r, "", modified, bytes.NewReader(data))
+	return nil
+}
+
+// handlerLanguages is handler for GET:/languages request.
+func (s *Service) handlerLanguages(w http.ResponseWriter, r *http.Request) error {
+	return s.writeLanguages(w, r, s.LanguageGroups())
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+// LayoutConfig is keyboard layout settings.
+// Detect enables replies with corrected text to messages
+// which were likely typed with wrong keyboard layout, like "ghbdtn".
+type LayoutConfig struct {
+	Detect bool `json:"detect" yaml:"detect" toml:"detect"`
+}
+
+// commandText returns original text of a bot's command after its name, like "text" of "tr layout text".
+func commandText(text string) string {
+	notSpace := func(r rune) bool { return !unicode.IsSpace(r) }
+	text = strings.TrimSpace(text)
+	for i := 0; i < 2; i++ {
+		text = strings.TrimLeftFunc(strings.TrimLeftFunc(text, notSpace), unicode.IsSpace)
+	}
+	return text
+}
+
+// layoutCommand returns the text with switched keyboard layout,
+// the last message of the channel is used if the text is empty.
+func (s *Service) layoutCommand(event *EventRequest) string {
+	text := commandText(event.Text)
+	if text == "" {
+		last, ok := s.current().history.last(event.Channel, "")
+		if !ok {
+			return "no recent messages"
+		}
+		text = last
+	}
+	return translator.SwitchLayout(text)
+}
+
+// fixLayout returns corrected text of the message if the detection is enabled
+// and the message was likely typed with wrong keyboard layout.
+func fixLayout(cfg *LayoutConfig, text string) (string, bool) {
+	if !cfg.Detect || !translator.IsWrongLayout(text) {
+		return text, false
+	}
+	return translator.SwitchLayout(text), true
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCommandText(t *testing.T) {
+	testValues := map[string]string{
+		"tr layout ghbdtn":           "ghbdtn",
+		"  tr   layout  rfr\n ltkf ": "rfr\n ltkf",
+		"tr layout":                  "",
+	}
+	for k, v := range testValues {
+		if r := commandText(k); r != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, r, v)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	cfg := *s.current().cfg
+	cfg.History = HistoryConfig{Size: 5}
+	cfg.Layout = LayoutConfig{Detect: true}
+	cfg.Auto = AutoConfig{Users: map[string]string{"alice": "en"}, Ignore: []string{"bot"}}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	events := []struct {
+		username string
+		text     string
+		want     string
+	}{
+		{"bob", "tr layout", "no recent messages"},
+		{"bob", "tr layout ghbdtn", "привет"},
+		{"bob", "hello world", ""},
+		{"bob", "tr layout", "руддщ цщкдв"},
+		{"bob", "ghbdtn? rfr ltkf&", "привет, как дела?"},
+		{"bob", "ru-en ^", "[привет, как дела?]"},
+		// corrected text is translated automatically
+		{"alice", "ghbdtn? rfr ltkf&", "[привет, как дела?]"},
+		{"bot", "ghbdtn? rfr ltkf&", ""},
+	}
+	for i, e := range events {
+		result, err := s.HandleEvent(ctx, &EventRequest{Username: e.username, Channel: "main", Text: e.text})
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", i, err)
+		}
+		if result != e.want {
+			t.Errorf("wrong result for %v: %q, expected %q", i, result, e.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket requests rate limiter.
+type limiter struct {
+	sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns new rate limiter, it is nil if there are no limits.
+func newLimiter(cfg LimitsConfig) *limiter {
+	if cfg.Rate == 0 {
+		return nil
+	}
+	return &limiter{
+		rate:   float64(cfg.Rate) / 60,
+		burst:  float64(cfg.Burst),
+		tokens: float64(cfg.Burst),
+		last:   time.Now(),
+	}
+}
+
+// allow returns true if one more request is allowed now.
+func (l *limiter) allow() bool {
+	if l == nil {
+		return true
+	}
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
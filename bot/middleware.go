@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	// RequestIDHeader is HTTP header with request ID.
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey is context key of request ID
+	requestIDKey ctxKey = "requestID"
+	// defaultBodyLimit is default maximum size of request body (bytes)
+	defaultBodyLimit = 64 << 10
+)
+
+// ctxKey is a type of context keys.
+type ctxKey string
+
+// validRequestID is a pattern of request ID which can be accepted from a client.
+var validRequestID = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// statusRecorder saves response status code.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader saves the status code and writes it.
+func (sr *statusRecorder) WriteHeader(code int) {
+	sr.code = code
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+// RequestID returns request ID from the context.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// requestID sets request ID from RequestIDHeader or a new random one.
+func (s *Service) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+// logging writes log info to console.
+func (s *Service) logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(sr, r)
+		s.loggerInfo.Printf("%-5v %v\t%-12v\t%v\t%v",
+			r.Method,
+			sr.code,
+			time.Since(start),
+			r.URL.String(),
+			RequestID(r.Context()),
+		)
+	})
+}
+
+// recovery replies internal server error if a handler panics.
+func (s *Service) recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				s.stats.Get("panics").(*expvar.Int).Add(1)
+				s.loggerError.Printf("panic [%v]: %v", RequestID(r.Context()), rec)
+				replyError(w, r, &httpError{
+					code: http.StatusInternalServerError,
+					msg:  http.StatusText(http.StatusInternalServerError),
+				})
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// metrics counts requests by route and status code and their durations.
+func (s *Service) metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(sr, r)
+		route := r.Pattern
+		if route == "" {
+			route = "unknown"
+		}
+		s.stats.Get("requests").(*expvar.Map).Add(fmt.Sprintf("%v %v", route, sr.code), 1)
+		s.stats.Get("durations").(*expvar.Map).AddFloat(route, time.Since(start).Seconds())
+	})
+}
+
+// bodyLimit limits request body size.
+func (s *Service) bodyLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := int64(s.current().cfg.Limits.Body)
+		if limit == 0 {
+			limit = defaultBodyLimit
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientCert verifies client certificate if it is required.
+func (s *Service) clientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkClient(&s.current().cfg.TLS, r); err != nil {
+			replyError(w, r, &httpError{code: http.StatusForbidden, msg: err.Error()})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// auth checks bearer token and request signature if they are configured.
+func (s *Service) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := &s.current().cfg.Auth
+		if cfg.Token == "" && cfg.Secret == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				replyError(w, r, &httpError{
+					code: http.StatusRequestEntityTooLarge,
+					msg:  fmt.Sprintf("request is too large, maximum is %v bytes", maxErr.Limit),
+				})
+				return
+			}
+			replyError(w, r, err)
+			return
+		}
+		if err = s.authenticate(cfg, r, body); err != nil {
+			replyError(w, r, &httpError{code: http.StatusUnauthorized, msg: err.Error()})
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimit rejects requests over configured limits.
+func (s *Service) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.current().limiter.allow() {
+			replyError(w, r, &httpError{code: http.StatusTooManyRequests, msg: "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewares(t *testing.T) {
+	t.Parallel()
+	s := newTestService("")
+	s.current().cfg.Limits.Body = 16
+	panicHandler := s.handle(func(w http.ResponseWriter, r *http.Request) error {
+		panic("test")
+	})
+	h := chain(panicHandler, s.requestID, s.logging, s.recovery, s.metrics, s.bodyLimit)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(RequestIDHeader, "test-id")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("wrong status: %v", w.Code)
+	}
+	if id := w.Header().Get(RequestIDHeader); id != "test-id" {
+		t.Errorf("wrong request ID: %v", id)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(RequestIDHeader, "bad id\n")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if id := w.Header().Get(RequestIDHeader); len(id) != 16 {
+		t.Errorf("wrong generated request ID: %v", id)
+	}
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	res, err := http.Post(ts.URL+"/event", "application/json", strings.NewReader(`{"text": "en-ru very long text"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("wrong status for too large request: %v", res.StatusCode)
+	}
+	res, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	metrics := struct {
+		Requests map[string]int `json:"requests"`
+		Panics   int            `json:"panics"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&metrics); err != nil {
+		t.Fatalf("metrics decode error: %v", err)
+	}
+	if metrics.Panics != 2 {
+		t.Errorf("wrong panics counter: %v", metrics.Panics)
+	}
+	if n := metrics.Requests["POST /event 413"]; n != 1 {
+		t.Errorf("wrong requests counter: %v", metrics.Requests)
+	}
+}
@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvPrefix is a prefix of configuration environment variables.
+	EnvPrefix = "TRBOT_"
+	// fileSuffix is a suffix of a name which value should be read from a file.
+	fileSuffix = "_file"
+)
+
+// Overrides is a set of configuration values by their names,
+// a name is a path of JSON keys joined by "_", for example "tkey", "tkey_file"
+// or "providers_yandex_tkey" (only for providers from the configuration file).
+// A value of a name with "_file" suffix is a path of a file with the value,
+// it is useful for secrets.
+type Overrides map[string]string
+
+// configField is a settable configuration field.
+type configField struct {
+	name  string
+	value reflect.Value
+}
+
+// configFields returns all settable fields of v with their names.
+func configFields(prefix string, v reflect.Value) []configField {
+	var fields []configField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + tag
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			fields = append(fields, configFields(name+"_", v.Field(i))...)
+			continue
+		case reflect.Map:
+			// only existing items of map[string]*struct can be overridden
+			if f.Type.Key().Kind() == reflect.String && f.Type.Elem().Kind() == reflect.Ptr &&
+				f.Type.Elem().Elem().Kind() == reflect.Struct {
+				m := v.Field(i)
+				keys := m.MapKeys()
+				sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+				for _, key := range keys {
+					if item := m.MapIndex(key); !item.IsNil() {
+						fields = append(fields, configFields(name+"_"+key.String()+"_", item.Elem())...)
+					}
+				}
+			}
+			continue
+		}
+		fields = append(fields, configField{name: name, value: v.Field(i)})
+	}
+	return fields
+}
+
+// set parses a string value and sets it to the field.
+func (f *configField) set(value string) error {
+	v := f.value
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %v", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// lookupValue returns a value by the name from lookup function,
+// a direct value has priority over a value from a file.
+func lookupValue(name string, lookup func(string) (string, bool)) (string, bool, error) {
+	if value, ok := lookup(name); ok {
+		return value, true, nil
+	}
+	file, ok := lookup(name + fileSuffix)
+	if !ok {
+		return "", false, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// override sets configuration fields from lookup function results.
+func (c *Config) override(lookup func(string) (string, bool)) error {
+	for _, f := range configFields("", reflect.ValueOf(c).Elem()) {
+		value, ok, err := lookupValue(f.name, lookup)
+		if err != nil {
+			return fmt.Errorf("%v: %v", f.name, err)
+		}
+		if !ok {
+			continue
+		}
+		if err = f.set(value); err != nil {
+			return fmt.Errorf("%v: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+// EnvName returns environment variable name of configuration field.
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// envLookup looks for configuration values in environment variables.
+func envLookup(name string) (string, bool) {
+	return os.LookupEnv(EnvName(name))
+}
+
+// lookup looks for configuration values in overrides.
+func (o Overrides) lookup(name string) (string, bool) {
+	value, ok := o[name]
+	return value, ok
+}
+
+// Flags defines command-line flags for all configuration fields in fs,
+// for example "-tkey" and "-tkey-file". Values of used flags are saved to o.
+func (o Overrides) Flags(fs *flag.FlagSet) {
+	for _, f := range configFields("", reflect.ValueOf(&Config{}).Elem()) {
+		for _, name := range []string{f.name, f.name + fileSuffix} {
+			key := name
+			usage := fmt.Sprintf("configuration value %q (env %v)", f.name, EnvName(name))
+			if strings.HasSuffix(name, fileSuffix) {
+				usage = fmt.Sprintf("file with configuration value %q (env %v)", f.name, EnvName(name))
+			}
+			fs.Func(strings.Replace(name, "_", "-", -1), usage, func(value string) error {
+				o[key] = value
+				return nil
+			})
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Verbosity and mode values of user's preferences.
+const (
+	verbosityFull  = "full"
+	verbosityShort = "short"
+	modeAuto       = "auto"
+	modeTranslate  = "translate"
+	modeDictionary = "dictionary"
+)
+
+// PrefsConfig is users preferences settings.
+// File keeps preferences between restarts, empty value means memory only storage.
+type PrefsConfig struct {
+	File string `json:"file" yaml:"file" toml:"file"`
+}
+
+// Preferences are user's settings. Direction is used if a command omits it,
+// Verbosity "short" removes additional dictionary translations, Mode selects
+// translation or dictionary for commands without flags, Auto is a target
+// language of automatic translation or "off" to disable configured one.
+type Preferences struct {
+	Direction string `json:"direction,omitempty"`
+	Verbosity string `json:"verbosity,omitempty"`
+	Mode      string `json:"mode,omitempty"`
+	Auto      string `json:"auto,omitempty"`
+}
+
+// String returns human readable preferences.
+func (p *Preferences) String() string {
+	values := []string{
+		"default " + orNone(p.Direction),
+		"verbosity " + orValue(p.Verbosity, verbosityFull),
+		"mode " + orValue(p.Mode, modeAuto),
+		"auto " + orNone(p.Auto),
+	}
+	return strings.Join(values, ", ")
+}
+
+// orValue returns value or defaultValue if value is empty.
+func orValue(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// orNone returns value or "none" if value is empty.
+func orNone(value string) string {
+	return orValue(value, "none")
+}
+
+// mode returns translation mode of a command without flags.
+// Dictionary mode is used only for a text which fits lookup limits, longer one is translated.
+func (p *Preferences) mode(limits *LimitsConfig, text string, isTr bool) bool {
+	switch p.Mode {
+	case modeTranslate:
+		return true
+	case modeDictionary:
+		return limits.checkText(text, false) != nil
+	}
+	return isTr
+}
+
+// autoTarget returns target language of automatic translation,
+// configured one is used if the user hasn't set it.
+func (p *Preferences) autoTarget(cfg *AutoConfig, username string) string {
+	switch p.Auto {
+	case "":
+		return cfg.Users[username]
+	case autoOff:
+		return ""
+	}
+	return p.Auto
+}
+
+// prefsStore is a storage of users preferences.
+type prefsStore struct {
+	sync.Mutex
+	users map[string]Preferences
+}
+
+// newPrefsStore returns new empty preferences storage.
+func newPrefsStore() *prefsStore {
+	return &prefsStore{users: make(map[string]Preferences)}
+}
+
+// get returns preferences of the user.
+func (ps *prefsStore) get(username string) Preferences {
+	ps.Lock()
+	defer ps.Unlock()
+	return ps.users[username]
+}
+
+// update changes preferences of the user by f, empty preferences are removed.
+func (ps *prefsStore) update(username string, f func(p *Preferences)) Preferences {
+	ps.Lock()
+	defer ps.Unlock()
+	p := ps.users[username]
+	f(&p)
+	if p == (Preferences{}) {
+		delete(ps.users, username)
+	} else {
+		ps.users[username] = p
+	}
+	return p
+}
+
+// save writes preferences to the file, it's replaced atomically.
+func (ps *prefsStore) save(file string) error {
+	if file == "" {
+		return nil
+	}
+	ps.Lock()
+	data, err := json.MarshalIndent(ps.users, "", "  ")
+	ps.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, data)
+}
+
+// load reads preferences from the file, a missing file is not an error.
+func (ps *prefsStore) load(file string) error {
+	if file == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	users := make(map[string]Preferences)
+	if err = json.Unmarshal(data, &users); err != nil {
+		return err
+	}
+	ps.Lock()
+	defer ps.Unlock()
+	for username, p := range users {
+		ps.users[username] = p
+	}
+	return nil
+}
+
+// article returns dictionary article with user's verbosity.
+func (p *Preferences) article(text string) string {
+	if p.Verbosity != verbosityShort {
+		return text
+	}
+	return shortResult(text)
+}
+
+// shortResult removes additional translations (indented lines) of dictionary article.
+func shortResult(text string) string {
+	lines := strings.Split(text, "\n")
+	result := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "  ") {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// setPreference changes a preference by its name and returns a reply.
+func (s *Service) setPreference(username, name, value string) string {
+	var apply func(p *Preferences)
+	switch name {
+	case "default":
+		if value != autoOff && !isTranslit(value) && !s.isDirection(value, true) && !s.isDirection(value, false) {
+			return fmt.Sprintf("unknown direction %v", value)
+		}
+		apply = func(p *Preferences) { p.Direction = value }
+	case "verbosity":
+		if value != verbosityFull && value != verbosityShort {
+			return fmt.Sprintf("verbosity is %q or %q", verbosityFull, verbosityShort)
+		}
+		apply = func(p *Preferences) { p.Verbosity = value }
+	case "mode":
+		if value != modeAuto && value != modeTranslate && value != modeDictionary {
+			return fmt.Sprintf("mode is %q, %q or %q", modeAuto, modeTranslate, modeDictionary)
+		}
+		apply = func(p *Preferences) { p.Mode = value }
+	case "auto":
+		if value != autoOff && !s.isTarget(value) {
+			return fmt.Sprintf("unknown target language %v", value)
+		}
+		apply = func(p *Preferences) { p.Auto = value }
+	default:
+		return commandUsage
+	}
+	p := s.prefs.update(username, func(p *Preferences) {
+		apply(p)
+		// default values are not stored
+		if p.Direction == autoOff {
+			p.Direction = ""
+		}
+		if p.Verbosity == verbosityFull {
+			p.Verbosity = ""
+		}
+		if p.Mode == modeAuto {
+			p.Mode = ""
+		}
+	})
+	s.savePrefs()
+	return fmt.Sprintf("%v preferences: %v", username, p.String())
+}
+
+// savePrefs writes preferences to the configured file.
+func (s *Service) savePrefs() {
+	if err := s.prefs.save(s.current().cfg.Prefs.File); err != nil {
+		s.loggerError.Printf("preferences save error: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreferences(t *testing.T) {
+	t.Parallel()
+	s, upstream := newContractService(t)
+	defer upstream.Close()
+	file := filepath.Join(t.TempDir(), "prefs.json")
+	cfg := *s.current().cfg
+	cfg.Prefs = PrefsConfig{File: file}
+	cfg.History = HistoryConfig{Size: 10}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	events := []struct {
+		username string
+		text     string
+		want     string
+	}{
+		{"alice", "tr hello", ""},
+		// without default direction it's a normal message
+		{"alice", "^", ""},
+		{"alice", "tr set default xx-yy", "unknown direction xx-yy"},
+		{"alice", "tr set default en-ru", "alice preferences: default en-ru, verbosity full, mode auto, auto none"},
+		{"alice", "tr hello big world", "[hello big world]"},
+		{"bob", "some more words", ""},
+		{"alice", "^bob", "[some more words]"},
+		{"bob", "^^", ""},
+		{"alice", "^bob", "[^^]"},
+		{"alice", "en-ru time", "time(noun)\n[time] (noun)"},
+		{"alice", "tr set mode translate", "alice preferences: default en-ru, verbosity full, mode translate, auto none"},
+		{"alice", "en-ru time", "[time]"},
+		{"alice", "en-ru -d time", "time(noun)\n[time] (noun)"},
+		{"alice", "tr set mode dictionary", "alice preferences: default en-ru, verbosity full, mode dictionary, auto none"},
+		{"alice", "en-ru time", "time(noun)\n[time] (noun)"},
+		// a long text doesn't fit dictionary lookup, it's translated
+		{"alice", "en-ru hello how are you doing today", "[hello how are you doing today]"},
+		{"bob", "hello how are you doing today", ""},
+		{"alice", "^bob", "[hello how are you doing today]"},
+		{"alice", "tr set mode translate", "alice preferences: default en-ru, verbosity full, mode translate, auto none"},
+		{"alice", "tr set mode wrong", `mode is "auto", "translate" or "dictionary"`},
+		{"alice", "tr set auto ru", "alice preferences: default en-ru, verbosity full, mode translate, auto ru"},
+		{"alice", "hi", "[hi]"},
+		{"alice", "tr set verbosity short", "alice preferences: default en-ru, verbosity short, mode translate, auto ru"},
+		{"bob", "first line\n  indented line", ""},
+		// only dictionary articles are shortened
+		{"alice", "^bob", "[first line\n  indented line]"},
+		{"alice", "en-ru -d time", "time(noun)\n[time] (noun)"},
+		{"bob", "tr prefs", "bob preferences: default none, verbosity full, mode auto, auto none"},
+		{"bob", "tr reset", "bob preferences are reset"},
+	}
+	for i, e := range events {
+		result, err := s.HandleEvent(ctx, &EventRequest{Username: e.username, Text: e.text})
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", i, err)
+		}
+		if result != e.want {
+			t.Errorf("wrong result for %v: %q, expected %q", i, result, e.want)
+		}
+	}
+
+	// preferences are saved to the file on change
+	ps := newPrefsStore()
+	if err := ps.load(file); err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if p := ps.get("alice"); p != (Preferences{Direction: "en-ru", Verbosity: verbosityShort, Mode: modeTranslate, Auto: "ru"}) {
+		t.Errorf("wrong loaded preferences: %+v", p)
+	}
+	if len(ps.users) != 1 {
+		t.Errorf("empty preferences are saved: %v", ps.users)
+	}
+}
+
+func TestShortResult(t *testing.T) {
+	t.Parallel()
+	text := "time(noun)\nвремя (noun)\n  раз (noun)\n  тайм (noun)\nпора (noun)"
+	if r := shortResult(text); r != "time(noun)\nвремя (noun)\nпора (noun)" {
+		t.Errorf("wrong short result: %q", r)
+	}
+}
@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// Middleware is a wrapper of HTTP handler.
+type Middleware func(http.Handler) http.Handler
+
+// chain wraps h by middlewares, the first one is the outermost.
+func chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Handler returns HTTP handler with all service's routes.
+// Every route gets request ID, logging, panic recovery, metrics
+// and request body size limit; chat events, translation and lookup requests
+// also require client certificate, authentication and rate limit checks
+// if they are configured.
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /info", s.handle(s.handlerInfo))
+	mux.Handle("GET /metrics", s.handle(s.handlerMetrics))
+	mux.Handle("GET /languages", s.handle(s.handlerLanguages))
+	mux.Handle("POST /event", chain(s.handle(s.handlerEvent), s.clientCert, s.auth, s.rateLimit))
+	mux.Handle("POST /translate/batch", chain(s.handle(s.handlerBatch), s.clientCert, s.auth, s.rateLimit))
+	mux.Handle("POST /api/v1/translate", chain(s.handle(s.handlerAPITranslate), s.clientCert, s.auth, s.rateLimit))
+	mux.Handle("GET /api/v1/lookup", chain(s.handle(s.handlerAPILookup), s.clientCert, s.auth, s.rateLimit))
+	mux.Handle("GET /api/v1/languages", s.handle(s.handlerAPILanguages))
+	// REST API replies JSON errors for unknown routes and methods too
+	mux.Handle(apiPrefix, s.handle(s.handlerAPINotFound))
+	mux.Handle("/api/v1/translate", s.handle(methodNotAllowed(http.MethodPost)))
+	mux.Handle("/api/v1/lookup", s.handle(methodNotAllowed(http.MethodGet)))
+	mux.Handle("/api/v1/languages", s.handle(methodNotAllowed(http.MethodGet)))
+	return chain(mux, s.requestID, s.logging, s.recovery, s.metrics, s.bodyLimit)
+}
+
+// handlerMetrics is handler for GET:/metrics request.
+func (s *Service) handlerMetrics(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write([]byte(s.stats.String()))
+	return err
+}
+
+// newStats returns service's metrics storage.
+func newStats() *expvar.Map {
+	stats := new(expvar.Map).Init()
+	stats.Set("requests", new(expvar.Map).Init())
+	stats.Set("durations", new(expvar.Map).Init())
+	stats.Set("panics", new(expvar.Int))
+	stats.Set("usage", new(expvar.Map).Init())
+	return stats
+}
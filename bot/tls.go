@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultTLSReload is default period to check TLS files changes.
+const defaultTLSReload = time.Minute
+
+// TLSConfig is HTTPS server settings.
+// ClientCA enables client certificates verification for POST:/event requests,
+// ClientNames is an optional list of allowed client certificates names (CN or DNS SAN).
+// Reload is a period (seconds) to check files changes.
+type TLSConfig struct {
+	Cert        string   `json:"cert" yaml:"cert" toml:"cert"`
+	Key         string   `json:"key" yaml:"key" toml:"key"`
+	ClientCA    string   `json:"client_ca" yaml:"client_ca" toml:"client_ca"`
+	ClientNames []string `json:"client_names" yaml:"client_names" toml:"client_names"`
+	Reload      uint     `json:"reload" yaml:"reload" toml:"reload"`
+}
+
+// Enabled returns true if HTTPS is configured.
+func (c *TLSConfig) Enabled() bool {
+	return c.Cert != "" || c.Key != ""
+}
+
+// sameFiles returns true if certificate, key and client CA files are equal.
+func (c *TLSConfig) sameFiles(other *TLSConfig) bool {
+	return c.Cert == other.Cert && c.Key == other.Key && c.ClientCA == other.ClientCA
+}
+
+// validate returns TLS settings problems.
+func (c *TLSConfig) validate() []string {
+	var problems []string
+	if !c.Enabled() {
+		if c.ClientCA != "" {
+			problems = append(problems, "tls.client_ca: client certificates verification requires tls.cert and tls.key")
+		}
+		return problems
+	}
+	files := map[string]string{"tls.cert": c.Cert, "tls.key": c.Key, "tls.client_ca": c.ClientCA}
+	for _, name := range []string{"tls.cert", "tls.key", "tls.client_ca"} {
+		file := files[name]
+		if file == "" {
+			if name != "tls.client_ca" {
+				problems = append(problems, fmt.Sprintf("%v: file is required for HTTPS", name))
+			}
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			problems = append(problems, fmt.Sprintf("%v: %v", name, err))
+		}
+	}
+	return problems
+}
+
+// CertReloader keeps TLS certificate and client CA pool up to date with their files.
+type CertReloader struct {
+	mu      sync.RWMutex
+	cfg     TLSConfig
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time
+}
+
+// NewCertReloader loads TLS files.
+func NewCertReloader(cfg TLSConfig) (*CertReloader, error) {
+	cr := &CertReloader{cfg: cfg}
+	if err := cr.load(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// lastModified returns the latest modification time of TLS files.
+func (cr *CertReloader) lastModified() (time.Time, error) {
+	var last time.Time
+	for _, file := range []string{cr.cfg.Cert, cr.cfg.Key, cr.cfg.ClientCA} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return last, err
+		}
+		if t := info.ModTime(); t.After(last) {
+			last = t
+		}
+	}
+	return last, nil
+}
+
+// load reads certificate, key and client CA files.
+func (cr *CertReloader) load() error {
+	modTime, err := cr.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(cr.cfg.Cert, cr.cfg.Key)
+	if err != nil {
+		return err
+	}
+	var pool *x509.CertPool
+	if cr.cfg.ClientCA != "" {
+		data, err := ioutil.ReadFile(cr.cfg.ClientCA)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates in %v", cr.cfg.ClientCA)
+		}
+	}
+	cr.mu.Lock()
+	cr.cert, cr.pool, cr.modTime = &cert, pool, modTime
+	cr.mu.Unlock()
+	return nil
+}
+
+// Watch reloads TLS files if they are changed, it stops when ctx is done.
+// Failed reloads are logged and the previous certificates are kept.
+func (cr *CertReloader) Watch(ctx context.Context, logger *log.Logger) {
+	period := time.Duration(cr.cfg.Reload) * time.Second
+	if period == 0 {
+		period = defaultTLSReload
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := cr.lastModified()
+			if err != nil {
+				logger.Printf("TLS files check error: %v", err)
+				continue
+			}
+			cr.mu.RLock()
+			changed := modTime.After(cr.modTime)
+			cr.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err = cr.load(); err != nil {
+				logger.Printf("TLS files reload error, old ones are kept: %v", err)
+			}
+		}
+	}
+}
+
+// TLSConfig returns server TLS configuration which uses actual certificates.
+func (cr *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cr.mu.RLock()
+			defer cr.mu.RUnlock()
+			c := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cr.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if cr.pool != nil {
+				// client certificates are required only for POST:/event, see checkClient
+				c.ClientAuth = tls.VerifyClientCertIfGiven
+				c.ClientCAs = cr.pool
+			}
+			return c, nil
+		},
+	}
+}
+
+// checkClient verifies request's client certificate if it is required.
+func checkClient(cfg *TLSConfig, r *http.Request) error {
+	if cfg.ClientCA == "" {
+		return nil
+	}
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return errors.New("client certificate is required")
+	}
+	if len(cfg.ClientNames) == 0 {
+		return nil
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	names := append([]string{cert.Subject.CommonName}, cert.DNSNames...)
+	for _, allowed := range cfg.ClientNames {
+		for _, name := range names {
+			if name == allowed {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("client certificate %q is not allowed", cert.Subject.CommonName)
+}
@@ -0,0 +1,232 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a generated certificate with its PEM data.
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key error: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	parentCert, parentKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("certificate error: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate error: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key error: %v", err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("key pair error: %v", err)
+	}
+	return cert
+}
+
+func TestTLSValidate(t *testing.T) {
+	cfg := &TLSConfig{ClientCA: "ca.pem"}
+	if n := len(cfg.validate()); n != 1 {
+		t.Errorf("wrong number of problems: %v", n)
+	}
+	cfg = &TLSConfig{Cert: "/not/found/cert.pem"}
+	if n := len(cfg.validate()); n != 2 {
+		t.Errorf("wrong number of problems: %v", n)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "localhost", ca)
+	gateway := newTestCert(t, "gateway", ca)
+	other := newTestCert(t, "other", ca)
+	files := map[string][]byte{"ca.pem": ca.certPEM, "cert.pem": server.certPEM, "key.pem": server.keyPEM}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	upstream := upTestServices(t)
+	defer upstream.Close()
+	s := newTestService(upstream.URL)
+	cfg := s.current().cfg
+	cfg.TLS = TLSConfig{
+		Cert:        filepath.Join(dir, "cert.pem"),
+		Key:         filepath.Join(dir, "key.pem"),
+		ClientCA:    filepath.Join(dir, "ca.pem"),
+		ClientNames: []string{"gateway"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.InitLanguages(context.Background()); err != nil {
+		t.Fatalf("init langs errors: %v", err)
+	}
+	certs, err := NewCertReloader(cfg.TLS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: s.Handler(), TLSConfig: certs.TLSConfig()}
+	go srv.ServeTLS(ln, "", "")
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	testValues := map[string]struct {
+		cert *testCert
+		code int
+	}{
+		"no certificate":    {nil, http.StatusForbidden},
+		"not allowed name":  {other, http.StatusForbidden},
+		"allowed with name": {gateway, http.StatusCreated},
+	}
+	for name, v := range testValues {
+		tlsCfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if v.cert != nil {
+			tlsCfg.Certificates = []tls.Certificate{v.cert.tlsCertificate(t)}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}}
+		body := bytes.NewBufferString(`{"text": "en-ru some words"}`)
+		res, err := client.Post("https://"+ln.Addr().String()+"/event", "application/json", body)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", name, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode != v.code {
+			t.Errorf("%v: wrong status %v, expected %v", name, res.StatusCode, v.code)
+		}
+	}
+}
+
+func TestCertReloaderWatch(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	first, second := newTestCert(t, "first", ca), newTestCert(t, "second", ca)
+	cfg := TLSConfig{Cert: filepath.Join(dir, "cert.pem"), Key: filepath.Join(dir, "key.pem"), Reload: 1}
+	write := func(c *testCert, modTime time.Time) {
+		for file, data := range map[string][]byte{cfg.Cert: c.certPEM, cfg.Key: c.keyPEM} {
+			if err := ioutil.WriteFile(file, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(file, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	write(first, time.Now().Add(-time.Minute))
+	certs, err := NewCertReloader(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go certs.Watch(ctx, log.New(ioutil.Discard, "", 0))
+
+	write(second, time.Now())
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c, err := certs.TLSConfig().GetConfigForClient(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if bytes.Equal(c.Certificates[0].Certificate[0], second.cert.Raw) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Error("certificate is not reloaded")
+}
+
+func TestReloadTLSFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "localhost", ca)
+	files := map[string][]byte{"ca.pem": ca.certPEM, "cert.pem": server.certPEM, "key.pem": server.keyPEM}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := newTestService("")
+	cfg := *s.Config()
+	cfg.TLS = TLSConfig{
+		Cert:        filepath.Join(dir, "cert.pem"),
+		Key:         filepath.Join(dir, "key.pem"),
+		ClientCA:    filepath.Join(dir, "ca.pem"),
+		ClientNames: []string{"gateway"},
+	}
+	if err := s.Reload(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// HTTPS server is not started, client certificates can't be required
+	tls := s.Config().TLS
+	if tls.Enabled() || tls.ClientCA != "" {
+		t.Errorf("TLS files are changed without restart: %+v", tls)
+	}
+	if len(tls.ClientNames) != 1 {
+		t.Errorf("client names are not reloaded: %+v", tls)
+	}
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+// TranslitConfig is transliteration settings.
+// Detransliterate converts Latin script text of Russian, Ukrainian
+// or Belarusian source language to Cyrillic before translation, like "ru-en privet".
+type TranslitConfig struct {
+	Detransliterate bool `json:"detransliterate" yaml:"detransliterate" toml:"detransliterate"`
+}
+
+// isTranslit returns true if direction is a transliteration one like "ru-lat" or "lat-ru".
+func isTranslit(direction string) bool {
+	source, target, ok := strings.Cut(direction, "-")
+	if !ok {
+		return false
+	}
+	if source == translator.Latin {
+		source, target = target, source
+	}
+	return target == translator.Latin && contains(translator.TranslitLanguages(), source)
+}
+
+// transliterate returns local transliteration result of the command without provider's request.
+func transliterate(cmd *translator.Command) (string, error) {
+	source, target, _ := strings.Cut(cmd.Direction, "-")
+	if source == translator.Latin {
+		return translator.Detransliterate(target, cmd.Text)
+	}
+	return translator.Transliterate(source, cmd.Text)
+}
+
+// detransliterate returns Cyrillic text of the command if it's enabled,
+// the source language supports transliteration and the text is written in Latin script.
+func detransliterate(cfg *TranslitConfig, cmd *translator.Command) string {
+	if !cfg.Detransliterate || !translator.IsLatin(cmd.Text) {
+		return cmd.Text
+	}
+	source, _, _ := strings.Cut(cmd.Direction, "-")
+	text, err := translator.Detransliterate(source, cmd.Text)
+	if err != nil {
+		return cmd.Text
+	}
+	return text
+}
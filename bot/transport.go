@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// default HTTP client transport settings
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleTimeout         = 90 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+	defaultTLSTimeout          = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+// TransportConfig is HTTP client settings for upstream requests.
+// Timeouts are in seconds, zero values mean defaults.
+// Empty Proxy means proxy from environment variables (HTTP_PROXY, HTTPS_PROXY, NO_PROXY),
+// CAFile is a PEM bundle of additional trusted certificates.
+type TransportConfig struct {
+	MaxIdleConns        uint   `json:"max_idle_conns" yaml:"max_idle_conns" toml:"max_idle_conns"`
+	MaxIdleConnsPerHost uint   `json:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host" toml:"max_idle_conns_per_host"`
+	IdleTimeout         uint   `json:"idle_timeout" yaml:"idle_timeout" toml:"idle_timeout"`
+	DialTimeout         uint   `json:"dial_timeout" yaml:"dial_timeout" toml:"dial_timeout"`
+	TLSTimeout          uint   `json:"tls_timeout" yaml:"tls_timeout" toml:"tls_timeout"`
+	KeepAlive           uint   `json:"keep_alive" yaml:"keep_alive" toml:"keep_alive"`
+	DisableKeepAlives   bool   `json:"disable_keep_alives" yaml:"disable_keep_alives" toml:"disable_keep_alives"`
+	DisableHTTP2        bool   `json:"disable_http2" yaml:"disable_http2" toml:"disable_http2"`
+	CAFile              string `json:"ca_file" yaml:"ca_file" toml:"ca_file"`
+	Proxy               string `json:"proxy" yaml:"proxy" toml:"proxy"`
+}
+
+// seconds returns duration of value seconds or default one for zero value.
+func seconds(value uint, d time.Duration) time.Duration {
+	if value == 0 {
+		return d
+	}
+	return time.Duration(value) * time.Second
+}
+
+// orDefault returns value or default one for zero value.
+func orDefault(value uint, d int) int {
+	if value == 0 {
+		return d
+	}
+	return int(value)
+}
+
+// validate returns transport settings problems.
+func (c *TransportConfig) validate() []string {
+	var problems []string
+	if c.Proxy != "" {
+		if u, err := url.Parse(c.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf(
+				"transport.proxy: %q is not a valid URL, for example http://proxy:3128", c.Proxy))
+		}
+	}
+	if c.CAFile != "" {
+		if _, err := os.Stat(c.CAFile); err != nil {
+			problems = append(problems, fmt.Sprintf("transport.ca_file: %v", err))
+		}
+	}
+	return problems
+}
+
+// newHTTPClient returns HTTP client for upstream requests.
+func newHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		data, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates in %v", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	dialer := &net.Dialer{
+		Timeout:   seconds(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: seconds(cfg.KeepAlive, defaultKeepAlive),
+	}
+	tr := &http.Transport{
+		Proxy:               proxy,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: seconds(cfg.TLSTimeout, defaultTLSTimeout),
+		MaxIdleConns:        orDefault(cfg.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost: orDefault(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		IdleConnTimeout:     seconds(cfg.IdleTimeout, defaultIdleTimeout),
+		DisableKeepAlives:   cfg.DisableKeepAlives,
+		ForceAttemptHTTP2:   !cfg.DisableHTTP2,
+	}
+	if cfg.DisableHTTP2 {
+		// non-nil empty map disables HTTP/2
+		tr.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return &http.Client{Transport: tr}, nil
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"encoding/json"
+	"expvar"
+	"io/ioutil"
+	"os"
+)
+
+// usage returns upstream usage counters: sent characters by provider name.
+func (s *Service) usage() *expvar.Map {
+	return s.stats.Get("usage").(*expvar.Map)
+}
+
+// saveUsage writes usage counters to the file.
+func (s *Service) saveUsage(file string) error {
+	if file == "" {
+		return nil
+	}
+	counters := make(map[string]int64)
+	s.usage().Do(func(kv expvar.KeyValue) {
+		counters[kv.Key] = kv.Value.(*expvar.Int).Value()
+	})
+	data, err := json.Marshal(counters)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, data)
+}
+
+// loadUsage reads usage counters from the file, a missing file is not an error.
+func (s *Service) loadUsage(file string) error {
+	if file == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	counters := make(map[string]int64)
+	if err = json.Unmarshal(data, &counters); err != nil {
+		return err
+	}
+	for name, value := range counters {
+		s.usage().Add(name, value)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Radio-t chat translation bot.
+// It translates required sentences or words using Yandex translate API.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+
+	"github.com/z0rr0/transalation-bot/bot"
+)
+
+var (
+	// Version is program version
+	Version = ""
+	// Revision is revision number
+	Revision = ""
+	// BuildDate is build date
+	BuildDate = ""
+	// GoVersion is runtime Go language version
+	GoVersion = runtime.Version()
+
+	// logFile is opened log file
+	logFile *os.File
+	// internal loggers
+	loggerError = log.New(os.Stderr, fmt.Sprintf("ERROR [%v]: ", bot.Name),
+		log.Ldate|log.Ltime|log.Lshortfile)
+	loggerInfo = log.New(os.Stdout, fmt.Sprintf("INFO [%v]: ", bot.Name),
+		log.Ldate|log.Ltime|log.Lshortfile)
+)
+
+// reloader calls reload on SIGHUP signals until ctx is done.
+func reloader(ctx context.Context, reload func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	defer signal.Stop(c)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c:
+			reload()
+		}
+	}
+}
+
+// setupLogging sets loggers outputs, previous log file is closed.
+func setupLogging(cfg bot.LoggingConfig) error {
+	var (
+		infoOut, errorOut io.Writer = os.Stdout, os.Stderr
+		f                 *os.File
+		err               error
+	)
+	if cfg.File != "" {
+		f, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		infoOut, errorOut = f, f
+	}
+	if cfg.Quiet {
+		infoOut = ioutil.Discard
+	}
+	loggerInfo.SetOutput(infoOut)
+	loggerError.SetOutput(errorOut)
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = f
+	return nil
+}
+
+// reloadConfig re-reads configuration file and applies it to the service.
+func reloadConfig(srv *bot.Service, file string, overrides bot.Overrides) {
+	cfg, err := bot.ReadConfig(file, overrides)
+	if err != nil {
+		loggerError.Printf("configuration reload error, old one is kept: %v", err)
+		return
+	}
+	if err = srv.Reload(cfg); err != nil {
+		loggerError.Printf("configuration is rejected, old one is kept: %v", err)
+		return
+	}
+	if err = setupLogging(cfg.Logging); err != nil {
+		loggerError.Printf("logging error: %v", err)
+	}
+	if err = srv.InitLanguages(context.Background()); err != nil {
+		loggerError.Printf("languages reload error, old ones are kept: %v", err)
+	}
+	loggerInfo.Printf("configuration is reloaded: %v", file)
+}
+
+// check prints configuration check result and returns exit code.
+func check(err error) int {
+	if err == nil {
+		fmt.Println("configuration is valid")
+		return 0
+	}
+	if problems, ok := err.(bot.ValidationError); ok {
+		fmt.Fprintln(os.Stderr, "invalid configuration:")
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "\t%v\n", p)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "configuration error: %v\n", err)
+	}
+	return 1
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run starts the service and returns exit code,
+// it is not zero if the service is terminated abnormally.
+func run() (code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			loggerError.Printf("abnormal termination [%v]: \n\t%v\n", Version, r)
+			code = 1
+		}
+	}()
+	version := flag.Bool("version", false, "show version")
+	checkConfig := flag.Bool("check-config", false, "validate configuration and exit")
+	configFile, ok := os.LookupEnv(bot.EnvName("config"))
+	if !ok {
+		configFile = bot.ConfigName
+	}
+	config := flag.String("config", configFile, "configuration file, empty value - no file (env TRBOT_CONFIG)")
+	overrides := bot.Overrides{}
+	overrides.Flags(flag.CommandLine)
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("\tVersion: %v\n\tRevision: %v\n\tBuild date: %v\n\tGo version: %v\n",
+			Version, Revision, BuildDate, GoVersion)
+		return 0
+	}
+	cfg, err := bot.ReadConfig(*config, overrides)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if *checkConfig {
+		return check(err)
+	}
+	if err != nil {
+		loggerError.Panicf("configuration error: %v", err)
+	}
+	if err = setupLogging(cfg.Logging); err != nil {
+		loggerError.Panicf("logging error: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv, err := bot.NewService(cfg, loggerInfo, loggerError)
+	if err != nil {
+		loggerError.Panicf("service error: %v", err)
+	}
+	err = srv.InitLanguages(ctx)
+	if err != nil {
+		loggerError.Panicf("no languages: %v", err)
+	}
+	// server
+	server := &http.Server{
+		Addr:           cfg.Addr(),
+		Handler:        srv.Handler(),
+		MaxHeaderBytes: 1 << 20, // 1MB
+		ErrorLog:       loggerError,
+	}
+	// background workers are stopped after in-flight requests are drained
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		bgCancel()
+		wg.Wait()
+		if err := srv.Close(); err != nil {
+			loggerError.Println(err)
+			code = 1
+		}
+	}()
+	background := func(f func(ctx context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(bgCtx)
+		}()
+	}
+	if cfg.TLS.Enabled() {
+		certs, err := bot.NewCertReloader(cfg.TLS)
+		if err != nil {
+			loggerError.Panicf("TLS error: %v", err)
+		}
+		server.TLSConfig = certs.TLSConfig()
+		background(func(ctx context.Context) {
+			certs.Watch(ctx, loggerError)
+		})
+	}
+	background(srv.Refresh)
+	background(srv.WatchGlossary)
+	background(func(ctx context.Context) {
+		reloader(ctx, func() {
+			reloadConfig(srv, *config, overrides)
+		})
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			// certificates are set by TLSConfig
+			errCh <- server.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- server.ListenAndServe()
+	}()
+	loggerInfo.Printf("running: version=%v [%v %v]\nListen: %v\n\n",
+		Version, GoVersion, Revision, server.Addr)
+	select {
+	case err = <-errCh:
+		loggerError.Printf("termination: %v [%v] reason: %v\n", Version, Revision, err)
+		return 1
+	case <-ctx.Done():
+		stop()
+		loggerInfo.Printf("termination: %v [%v] reason: interrupt signal\n", Version, Revision)
+	}
+	// drain timeout is used for the shutdown only, upstream requests have their own timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), srv.Config().ShutdownTimeout())
+	defer cancel()
+	loggerInfo.Println("graceful shutdown")
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		loggerError.Printf("graceful shutdown error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+module github.com/z0rr0/transalation-bot
+
+go 1.23
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
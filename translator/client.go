@@ -0,0 +1,187 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// YandexTranslateURL is Yandex translate API base URL.
+	YandexTranslateURL = "https://translate.yandex.net/api/v1.5/tr.json"
+	// YandexDictionaryURL is Yandex dictionary API base URL.
+	YandexDictionaryURL = "https://dictionary.yandex.net/api/v1/dicservice.json"
+	// DefaultTimeout is default timeout of API requests.
+	DefaultTimeout = 3 * time.Second
+	// DefaultUserAgent is default user-agent http header for API requests.
+	DefaultUserAgent = "translation-bot"
+	// MaxTextLength is maximum length of a text for translation (characters).
+	MaxTextLength = 10000
+)
+
+// Provider contains translation service's API URLs,
+// parameters encoding and authentication method.
+// MaxLength is maximum total length of texts in one translation request
+// (characters), zero value means MaxTextLength.
+type Provider struct {
+	Translate  string
+	Dictionary string
+	TrLangs    string
+	DictLangs  string
+	Encoding   Encoding
+	Auth       Auth
+	MaxLength  int
+}
+
+// Client is Yandex translate and dictionary API client.
+type Client struct {
+	Provider       *Provider
+	HTTPClient     *http.Client
+	TranslationKey string
+	DictionaryKey  string
+	Timeout        time.Duration
+	UserAgent      string
+}
+
+// Yandex returns Yandex services URLs.
+func Yandex() *Provider {
+	return NewYandex(YandexTranslateURL, YandexDictionaryURL)
+}
+
+// NewYandex returns Yandex compatible services URLs by their base URLs.
+func NewYandex(translateURL, dictionaryURL string) *Provider {
+	translateURL = strings.TrimRight(translateURL, "/")
+	dictionaryURL = strings.TrimRight(dictionaryURL, "/")
+	return &Provider{
+		Translate:  translateURL + "/translate",
+		Dictionary: dictionaryURL + "/lookup",
+		TrLangs:    translateURL + "/getLangs",
+		DictLangs:  dictionaryURL + "/getLangs",
+	}
+}
+
+// NewClient returns new API client with default HTTP client settings.
+func NewClient(p *Provider, trKey, dictKey string, timeout time.Duration) *Client {
+	tr := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	return &Client{
+		Provider:       p,
+		HTTPClient:     &http.Client{Transport: tr},
+		TranslationKey: trKey,
+		DictionaryKey:  dictKey,
+		Timeout:        timeout,
+		UserAgent:      DefaultUserAgent,
+	}
+}
+
+// call sends API request and decodes JSON response to result.
+func (c *Client) call(ctx context.Context, urlValue string, params url.Values, key string, result interface{}) error {
+	return c.Upstream().Call(ctx, urlValue, params, key, result)
+}
+
+// TranslationLangs loads translation languages.
+func (c *Client) TranslationLangs(ctx context.Context) (*LangsListTr, error) {
+	result := &LangsListTr{}
+	if err := c.call(ctx, c.Provider.TrLangs, url.Values{}, c.TranslationKey, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DictionaryLangs loads dictionary languages.
+func (c *Client) DictionaryLangs(ctx context.Context) (*LangsList, error) {
+	result := &LangsList{}
+	params := url.Values{"ui": {"en"}}
+	if err := c.call(ctx, c.Provider.DictLangs, params, c.DictionaryKey, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Translate returns text translation for the language direction.
+func (c *Client) Translate(ctx context.Context, direction, text string) (*JSONTrResp, error) {
+	result := &JSONTrResp{}
+	params := url.Values{
+		"lang":   {direction},
+		"text":   {text},
+		"format": {"plain"},
+	}
+	if err := c.call(ctx, c.Provider.Translate, params, c.TranslationKey, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TranslateBatch returns translations of texts for the language direction
+// in the same order. Several texts are sent in one request, they are split
+// into several requests if provider's MaxLength would be exceeded.
+func (c *Client) TranslateBatch(ctx context.Context, direction string, texts []string) ([]string, error) {
+	chunks, err := c.Provider.split(texts)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(texts))
+	for _, chunk := range chunks {
+		resp := &JSONTrResp{}
+		params := url.Values{
+			"lang":   {direction},
+			"text":   chunk,
+			"format": {"plain"},
+		}
+		if err := c.call(ctx, c.Provider.Translate, params, c.TranslationKey, resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Text) != len(chunk) {
+			return nil, fmt.Errorf("wrong number of translations %v, expected %v", len(resp.Text), len(chunk))
+		}
+		result = append(result, resp.Text...)
+	}
+	return result, nil
+}
+
+// split groups texts by requests, so total length of every group
+// is not bigger than provider's limit.
+func (p *Provider) split(texts []string) ([][]string, error) {
+	var (
+		chunks [][]string
+		length int
+	)
+	limit := p.MaxLength
+	if limit == 0 {
+		limit = MaxTextLength
+	}
+	start := 0
+	for i, text := range texts {
+		n := utf8.RuneCountInString(text)
+		if n > limit {
+			return nil, fmt.Errorf("text %v is too long: %v characters, maximum is %v", i, n, limit)
+		}
+		if length+n > limit {
+			chunks = append(chunks, texts[start:i])
+			start, length = i, 0
+		}
+		length += n
+	}
+	if start < len(texts) {
+		chunks = append(chunks, texts[start:])
+	}
+	return chunks, nil
+}
+
+// Lookup returns dictionary article of the text for the language direction.
+func (c *Client) Lookup(ctx context.Context, direction, text string) (*JSONTrDict, error) {
+	result := &JSONTrDict{}
+	params := url.Values{
+		"lang": {direction},
+		"text": {text},
+	}
+	if err := c.call(ctx, c.Provider.Dictionary, params, c.DictionaryKey, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func upTestProvider(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form error: %v", err)
+		}
+		if key := r.PostForm.Get("key"); key != "test" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		switch r.URL.Path {
+		case "/tr.json/getLangs":
+			fmt.Fprint(w, `{"dirs": ["ru-en", "en-ru"], "langs": {"ru": "русский", "en": "английский"}}`)
+		case "/dicservice.json/getLangs":
+			fmt.Fprint(w, `["ru-ru", "en-ru", "ru-en"]`)
+		case "/tr.json/translate":
+			texts, _ := json.Marshal(r.PostForm["text"])
+			fmt.Fprintf(w, `{"code": 200, "lang": %q, "text": %s}`, r.PostForm.Get("lang"), texts)
+		case "/dicservice.json/lookup":
+			fmt.Fprintf(w, `{"head": {}, "def": [{"text": %q, "pos": "noun", "tr": [{"text": "время", "pos": "noun"}]}]}`,
+				r.PostForm.Get("text"))
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+}
+
+func testProvider(baseURL string) *Provider {
+	return &Provider{
+		Translate:  baseURL + "/tr.json/translate",
+		Dictionary: baseURL + "/dicservice.json/lookup",
+		TrLangs:    baseURL + "/tr.json/getLangs",
+		DictLangs:  baseURL + "/dicservice.json/getLangs",
+	}
+}
+
+func TestClientLangs(t *testing.T) {
+	ts := upTestProvider(t)
+	defer ts.Close()
+	c := NewClient(testProvider(ts.URL), "test", "test", time.Second)
+	ctx := context.Background()
+
+	trLangs, err := c.TranslationLangs(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l := trLangs.Content(); len(l) != 2 || l[0] != "en-ru" {
+		t.Errorf("wrong translation languages: %v", l)
+	}
+	if name := trLangs.Langs["ru"]; name != "русский" {
+		t.Errorf("wrong language name: %v", name)
+	}
+	dictLangs, err := c.DictionaryLangs(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l := dictLangs.Content(); len(l) != 3 || l[0] != "en-ru" {
+		t.Errorf("wrong dictionary languages: %v", l)
+	}
+}
+
+func TestClientTranslate(t *testing.T) {
+	ts := upTestProvider(t)
+	defer ts.Close()
+	c := NewClient(testProvider(ts.URL), "test", "test", time.Second)
+	ctx := context.Background()
+
+	tr, err := c.Translate(ctx, "en-ru", "hello world")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := tr.String(); s != "hello world" {
+		t.Errorf("wrong translation: %v", s)
+	}
+	dict, err := c.Lookup(ctx, "en-ru", "time")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dict.Def) != 1 || dict.Def[0].Text != "time" {
+		t.Errorf("wrong lookup result: %+v", dict)
+	}
+	c.TranslationKey = "bad"
+	if _, err = c.Translate(ctx, "en-ru", "hello world"); err == nil {
+		t.Error("expected error for wrong key")
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	ts := upTestProvider(t)
+	defer ts.Close()
+	p := testProvider(ts.URL)
+	p.Translate = ts.URL + "/slow"
+	c := NewClient(p, "test", "test", 10*time.Millisecond)
+
+	if _, err := c.Translate(context.Background(), "en-ru", "hello world"); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestClientTranslateBatch(t *testing.T) {
+	ts := upTestProvider(t)
+	defer ts.Close()
+	p := testProvider(ts.URL)
+	p.MaxLength = 10
+	c := NewClient(p, "test", "test", time.Second)
+	ctx := context.Background()
+
+	texts := []string{"один", "два", "три", "четыре", "пять"}
+	chunks, err := p.split(texts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(chunks); n != 2 {
+		t.Errorf("wrong number of requests %v: %v", n, chunks)
+	}
+	result, err := c.TranslateBatch(ctx, "ru-en", texts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != len(texts) {
+		t.Fatalf("wrong number of translations: %v", result)
+	}
+	for i := range texts {
+		if result[i] != texts[i] {
+			t.Errorf("wrong translation order %v: %v", i, result)
+		}
+	}
+	if _, err := c.TranslateBatch(ctx, "ru-en", []string{"слишком длинный"}); err == nil {
+		t.Error("expected error for too long text")
+	}
+	if result, err := c.TranslateBatch(ctx, "ru-en", nil); err != nil || len(result) != 0 {
+		t.Errorf("unexpected result for empty batch: %v %v", result, err)
+	}
+}
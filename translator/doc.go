@@ -0,0 +1,5 @@
+// Package translator is a client library for Yandex translate and dictionary APIs.
+//
+// It contains API response types, a chat command parser ("xx-yy text")
+// and text formatters, so it can be used outside of the translation bot.
+package translator
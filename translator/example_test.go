@@ -0,0 +1,37 @@
+package translator_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+func ExampleParse() {
+	cmd, ok := translator.Parse("en-ru hello, world")
+	fmt.Println(ok, cmd.Direction, cmd.Text, cmd.IsTr)
+	// Output: true en-ru hello, world true
+}
+
+func ExampleIsDirection() {
+	languages := []string{"en-ru", "ru-en"}
+	fmt.Println(translator.IsDirection(languages, "en-ru"), translator.IsDirection(languages, "en-de"))
+	// Output: true false
+}
+
+func ExampleJSONTrResp_String() {
+	resp := &translator.JSONTrResp{Lang: "en-ru", Text: []string{"Здравствуй, Мир!"}}
+	fmt.Println(resp)
+	// Output: Здравствуй, Мир!
+}
+
+func ExampleClient_Translate() {
+	c := translator.NewClient(translator.Yandex(), "translation key", "dictionary key", 3*time.Second)
+	resp, err := c.Translate(context.Background(), "en-ru", "Hello, World!")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(resp)
+}
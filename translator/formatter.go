@@ -0,0 +1,45 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Separator is a lines separator of formatted results.
+const Separator = "\n"
+
+// String is an implementation of String() method for JSONTrResp pointer.
+func (jstr *JSONTrResp) String() string {
+	return strings.Join(jstr.Text, Separator)
+}
+
+// String is an implementation of String() method for JSONTrDict pointer.
+// It returns a pretty formatted string.
+func (jstrd *JSONTrDict) String() string {
+	var (
+		result, arResult []string
+		txtResult        string
+	)
+	tabSym := fmt.Sprintf("%v  ", Separator)
+
+	result = make([]string, len(jstrd.Def))
+	for i, def := range jstrd.Def {
+		ts := ""
+		if def.Ts != "" {
+			ts = fmt.Sprintf(" [%v] ", def.Ts)
+		}
+		txtResult = fmt.Sprintf("%v%v", def.Text, ts)
+		if def.Pos != "" {
+			txtResult += fmt.Sprintf("(%v)", def.Pos)
+		}
+		arResult = make([]string, len(def.Tr))
+		for j, tr := range def.Tr {
+			arResult[j] = tr.Text
+			if tr.Pos != "" {
+				arResult[j] += fmt.Sprintf(" (%v)", tr.Pos)
+			}
+		}
+		result[i] = fmt.Sprintf("%v%v%v", txtResult, Separator, strings.Join(arResult, tabSym))
+	}
+	return strings.Join(result, Separator)
+}
@@ -0,0 +1,32 @@
+package translator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONTrRespString(t *testing.T) {
+	resp := &JSONTrResp{Code: 200, Lang: "en-ru", Text: []string{"один", "два"}}
+	if s := resp.String(); s != "один"+Separator+"два" {
+		t.Errorf("wrong result: %q", s)
+	}
+}
+
+func TestJSONTrDictString(t *testing.T) {
+	data := `{"head": {}, "def": [
+		{"text": "time", "pos": "noun", "ts": "taɪm",
+		 "tr": [{"text": "время", "pos": "noun"}, {"text": "раз", "pos": "noun"}]}
+	]}`
+	dict := &JSONTrDict{}
+	if err := json.Unmarshal([]byte(data), dict); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	expected := "time [taɪm] " + Separator + "время (noun)" + Separator + "  раз (noun)"
+	if s := dict.String(); s != expected {
+		t.Errorf("wrong result: %q != %q", s, expected)
+	}
+	empty := &JSONTrDict{}
+	if s := empty.String(); s != "" {
+		t.Errorf("unexpected result: %q", s)
+	}
+}
@@ -0,0 +1,146 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// placeholder is a pattern of protected terms placeholders, spaces are allowed
+// because some providers add them around punctuation.
+var placeholder = regexp.MustCompile(`__\s*(\d+)\s*__`)
+
+// Glossary is a set of custom terms of a translation direction.
+// Terms are protected from translation, their values are fixed translations,
+// an empty value keeps the term as is. Dictionary entries override dictionary lookups.
+// Terms and words are case-insensitive.
+type Glossary struct {
+	Terms      map[string]string   `json:"terms" yaml:"terms" toml:"terms"`
+	Dictionary map[string][]string `json:"dictionary" yaml:"dictionary" toml:"dictionary"`
+	terms      map[string]string
+	words      map[string][]string
+	pattern    *regexp.Regexp
+}
+
+// Prepare builds the glossary search index, it must be called after terms or dictionary changes.
+func (g *Glossary) Prepare() error {
+	g.terms = make(map[string]string, len(g.Terms))
+	names := make([]string, 0, len(g.Terms))
+	for term, value := range g.Terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return errors.New("empty glossary term")
+		}
+		g.terms[strings.ToLower(term)] = value
+		names = append(names, regexp.QuoteMeta(term))
+	}
+	g.words = make(map[string][]string, len(g.Dictionary))
+	for word, values := range g.Dictionary {
+		if len(values) == 0 {
+			return fmt.Errorf("no translations of glossary word %q", word)
+		}
+		g.words[strings.ToLower(strings.TrimSpace(word))] = values
+	}
+	g.pattern = nil
+	if len(names) == 0 {
+		return nil
+	}
+	// the longest terms are matched first: "Radio-T Podcast" before "Radio-T"
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	pattern, err := regexp.Compile(`(?i)` + strings.Join(names, "|"))
+	if err != nil {
+		return err
+	}
+	g.pattern = pattern
+	return nil
+}
+
+// isWordRune returns true if r is a part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWholeWord returns true if text[start:end] is not a part of a longer word.
+func isWholeWord(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(r) {
+		return false
+	}
+	return true
+}
+
+// Protect replaces glossary terms of the text by placeholders like "__0__".
+// It returns the new text and values of placeholders for Restore.
+func (g *Glossary) Protect(text string) (string, []string) {
+	if g == nil || g.pattern == nil {
+		return text, nil
+	}
+	var (
+		b      strings.Builder
+		values []string
+		last   int
+	)
+	for _, loc := range g.pattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if !isWholeWord(text, start, end) {
+			continue
+		}
+		term := text[start:end]
+		value := g.terms[strings.ToLower(term)]
+		if value == "" {
+			value = term
+		}
+		b.WriteString(text[last:start])
+		b.WriteString("__" + strconv.Itoa(len(values)) + "__")
+		values = append(values, value)
+		last = end
+	}
+	if len(values) == 0 {
+		return text, nil
+	}
+	b.WriteString(text[last:])
+	return b.String(), values
+}
+
+// Restore replaces placeholders of the text by their values.
+func Restore(text string, values []string) string {
+	if len(values) == 0 {
+		return text
+	}
+	return placeholder.ReplaceAllStringFunc(text, func(s string) string {
+		i, err := strconv.Atoi(placeholder.FindStringSubmatch(s)[1])
+		if err != nil || i >= len(values) {
+			return s
+		}
+		return values[i]
+	})
+}
+
+// Lookup returns dictionary article of the word from the glossary.
+func (g *Glossary) Lookup(word string) (*JSONTrDict, bool) {
+	if g == nil {
+		return nil, false
+	}
+	word = strings.TrimSpace(word)
+	values, ok := g.words[strings.ToLower(word)]
+	if !ok {
+		return nil, false
+	}
+	article := JSONTrDictArticle{Text: word, Tr: make([]JSONTrDictItem, len(values))}
+	for i, value := range values {
+		article.Tr[i] = JSONTrDictItem{Text: value}
+	}
+	return &JSONTrDict{Def: []JSONTrDictArticle{article}}, true
+}
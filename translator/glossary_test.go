@@ -0,0 +1,82 @@
+package translator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGlossaryProtect(t *testing.T) {
+	g := &Glossary{Terms: map[string]string{
+		"Radio-T":         "",
+		"Radio-T Podcast": "",
+		"umputun":         "Умпутун",
+		"Го":              "",
+	}}
+	if err := g.Prepare(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testValues := []struct {
+		text      string
+		protected string
+		restored  string
+	}{
+		{"radio-t is great", "__0__ is great [radio-t]", "radio-t is great"},
+		{"Radio-T Podcast by Umputun", "__0__ by __1__ [Radio-T Podcast Умпутун]", "Radio-T Podcast by Умпутун"},
+		{"umputuns and Radio-Tx", "umputuns and Radio-Tx []", "umputuns and Radio-Tx"},
+		{"Го и Гоша", "__0__ и Гоша [Го]", "Го и Гоша"},
+		{"", " []", ""},
+	}
+	for _, v := range testValues {
+		text, values := g.Protect(v.text)
+		if r := fmt.Sprintf("%v %v", text, values); r != v.protected {
+			t.Errorf("wrong result for %q: %q, expected %q", v.text, r, v.protected)
+		}
+		if r := Restore(text, values); r != v.restored {
+			t.Errorf("wrong restored result for %q: %q, expected %q", v.text, r, v.restored)
+		}
+	}
+	var empty *Glossary
+	if text, values := empty.Protect("Radio-T"); text != "Radio-T" || values != nil {
+		t.Errorf("wrong result of empty glossary: %q, %v", text, values)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	values := []string{"Radio-T", "Умпутун"}
+	testValues := map[string]string{
+		"__0__ это __1__":  "Radio-T это Умпутун",
+		"__ 1 __ и __0__.": "Умпутун и Radio-T.",
+		"__2__ unknown":    "__2__ unknown",
+		"no placeholders":  "no placeholders",
+	}
+	for k, v := range testValues {
+		if r := Restore(k, values); r != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, r, v)
+		}
+	}
+	if r := Restore("__0__", nil); r != "__0__" {
+		t.Errorf("wrong result without values: %q", r)
+	}
+}
+
+func TestGlossaryLookup(t *testing.T) {
+	g := &Glossary{Dictionary: map[string][]string{"Podcast": {"подкаст", "передача"}}}
+	if err := g.Prepare(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	article, ok := g.Lookup(" podcast ")
+	if !ok {
+		t.Fatal("glossary word is not found")
+	}
+	expected := "podcast" + Separator + "подкаст" + Separator + "  передача"
+	if s := article.String(); s != expected {
+		t.Errorf("wrong article: %q, expected %q", s, expected)
+	}
+	if _, ok := g.Lookup("radio"); ok {
+		t.Error("unexpected glossary word")
+	}
+	invalid := &Glossary{Dictionary: map[string][]string{"empty": nil}}
+	if err := invalid.Prepare(); err == nil {
+		t.Error("expected error for empty translations")
+	}
+}
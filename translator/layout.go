@@ -0,0 +1,128 @@
+package translator
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// minLayoutBigrams is a minimum number of letter bigrams to detect wrong keyboard layout
+	minLayoutBigrams = 3
+	// minLayoutScore is a minimum share of common bigrams of switched text
+	minLayoutScore = 0.5
+	// minLayoutGain is a minimum difference of switched and original texts scores
+	minLayoutGain = 0.3
+)
+
+// Keys of QWERTY and ЙЦУКЕН keyboard layouts in the same order.
+const (
+	qwertyKeys = "`qwertyuiop[]asdfghjkl;'zxcvbnm,./" + `~QWERTYUIOP{}ASDFGHJKL:"ZXCVBNM<>?@#$^&`
+	jcukenKeys = "ёйцукенгшщзхъфывапролджэячсмитьбю." + `ЁЙЦУКЕНГШЩЗХЪФЫВАПРОЛДЖЭЯЧСМИТЬБЮ,"№;:?`
+)
+
+// Common letter bigrams of English and Russian languages.
+const (
+	englishBigrams = "th he in er an re on at en nd ti es or te of ed is it al ar st to nt ng " +
+		"se ha as ou io le ve co me de hi ri ro ic ne ea ra ce li ch ll be ma si om ur ca el ta la " +
+		"ns di fo ho pe ec pr no ct us ac ot il tr ly nc et ut ss so rs un lo wa ge ie wh ee wi em " +
+		"ad ol rt po we na ul ni ts mo ow pa im mi ai sh ir su id os iv ia am fi ci vi pl ig tu ev " +
+		"ld ry mp fe bl ab gh ty op wo sa ay ex ke fr oo av ag if ap gr od bo sp rd do uc bu ei ov " +
+		"by rm ep tt oc fa ef cu rn sc gi da yo cr cl du ga qu ue ff ba ey ls va um pp ua up lu go " +
+		"ht ru ug ds lt pi rc rr eg au ck ew mu br bi pt ak pu ui rg ib tl ny ki rk ys ob mm fu ph " +
+		"og ms ye ud mb ip ub oi rl gu dr hr cc tw ft wn nu af hu nn eo vo"
+	russianBigrams = "ст но то на ен ов ни ра во ко ро ет пр по ре ал ан ор ть ли ер ос ка от " +
+		"го ел не ол ат ва ин он ла та ри ил ем ом ле де те ит ог ск ес ны ве ми ль да об ло ак ма " +
+		"ти ие ас ам ед ой ый ий ая ые ся че ча ме ви ег ки ди вы бы мо ру зн ду до ну ше ую лю вс " +
+		"се сл мн ят ют тр хо чт уж же зд их ых уч чи чн бо ба бе бу ца це ще уп ул ус ут ум ун ур " +
+		"эт яв ям ях ив дн из ия им ич кт ку мы нн оп ош са си см сн со сп тв тс ту ты уд ук фо хи " +
+		"ци ша шл ью ял ян яз вл вн вр га гд ге гл гр др ев еш жа жд жи за зв зо иб иг ид ик ир ис " +
+		"ищ кл кр ло лу ля му нь ня од ок оч па пе пи пл пу ры су сь шь юб ей ез ек ел ож ск ье"
+)
+
+var (
+	layoutKeys       = newLayoutKeys(qwertyKeys, jcukenKeys)
+	layoutReverse    = newLayoutKeys(jcukenKeys, qwertyKeys)
+	englishBigramSet = newBigramSet(englishBigrams)
+	russianBigramSet = newBigramSet(russianBigrams)
+)
+
+// newLayoutKeys returns a map of keys from one keyboard layout to another.
+func newLayoutKeys(from, to string) map[rune]rune {
+	src, dst := []rune(from), []rune(to)
+	keys := make(map[rune]rune, len(src))
+	for i, r := range src {
+		keys[r] = dst[i]
+	}
+	return keys
+}
+
+// newBigramSet returns a set of space separated bigrams.
+func newBigramSet(values string) map[string]bool {
+	bigrams := make(map[string]bool)
+	for _, value := range strings.Fields(values) {
+		bigrams[value] = true
+	}
+	return bigrams
+}
+
+// isCyrillicText returns true if the text has more Cyrillic letters than others.
+func isCyrillicText(text string) bool {
+	var cyrillic, other int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.IsLetter(r):
+			other++
+		}
+	}
+	return cyrillic > other
+}
+
+// SwitchLayout returns the text typed with other keyboard layout: "ghbdtn" is "привет" and "руддщ" is "hello".
+// Mostly Latin text is converted from QWERTY to ЙЦУКЕН layout, mostly Cyrillic one - back.
+func SwitchLayout(text string) string {
+	keys := layoutKeys
+	if isCyrillicText(text) {
+		keys = layoutReverse
+	}
+	return strings.Map(func(r rune) rune {
+		if value, ok := keys[r]; ok {
+			return value
+		}
+		return r
+	}, text)
+}
+
+// bigramsScore returns a share of common bigrams in the words of the text and a number of all bigrams.
+func bigramsScore(text string, bigrams map[string]bool) (float64, int) {
+	var matched, total int
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		runes := []rune(word)
+		for i := 1; i < len(runes); i++ {
+			total++
+			if bigrams[string(runes[i-1:i+1])] {
+				matched++
+			}
+		}
+	}
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(matched) / float64(total), total
+}
+
+// IsWrongLayout returns true if the text was likely typed with wrong keyboard layout.
+// Common letter bigrams of the switched text are compared with bigrams of the original one.
+func IsWrongLayout(text string) bool {
+	original, switched := englishBigramSet, russianBigramSet
+	if isCyrillicText(text) {
+		original, switched = switched, original
+	}
+	score, total := bigramsScore(text, original)
+	if total < minLayoutBigrams {
+		return false
+	}
+	switchedScore, _ := bigramsScore(SwitchLayout(text), switched)
+	return switchedScore >= minLayoutScore && switchedScore-score >= minLayoutGain
+}
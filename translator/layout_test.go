@@ -0,0 +1,40 @@
+package translator
+
+import "testing"
+
+func TestSwitchLayout(t *testing.T) {
+	testValues := map[string]string{
+		"ghbdtn":            "привет",
+		"Ghbdtn? rfr ltkf&": "Привет, как дела?",
+		"'nj [jhjij":        "это хорошо",
+		"руддщ цщкдв":       "hello world",
+		"Руддщб цщкдв":      "Hello, world",
+		"42 :)":             "42 Ж)",
+		"":                  "",
+	}
+	for k, v := range testValues {
+		if r := SwitchLayout(k); r != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, r, v)
+		}
+	}
+}
+
+func TestIsWrongLayout(t *testing.T) {
+	testValues := map[string]bool{
+		"ghbdtn":                           true,
+		"ghbdtn? rfr ltkf&":                true,
+		"z ddjl ntrcn yf yt nen hfcrkflre": true,
+		"руддщ цщкдв":                      true,
+		"hello world":                      false,
+		"привет, как дела?":                false,
+		"the quick brown fox jumps":        false,
+		"lf":                               false,
+		"https://github.com/z0rr0":         false,
+		"12345":                            false,
+	}
+	for k, v := range testValues {
+		if r := IsWrongLayout(k); r != v {
+			t.Errorf("wrong result for %q: %v, expected %v", k, r, v)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package translator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LangDirect is a regexp pattern to detect language direction.
+var LangDirect = regexp.MustCompile(`[a-z]{2,3}-[a-z]{2,3}`)
+
+// LangDirects is a regexp pattern to detect language direction
+// with several target languages, like "en-ru,de". The direction must be a whole token
+// at the start of a line, so words like "en-masse" or "it-related" are not commands.
+var LangDirects = regexp.MustCompile(`^\s*([a-z]{2,3}-[a-z]{2,3}(?:,[a-z]{2,3})*)(?:\s|$)`)
+
+const (
+	// FlagTranslate is a flag after direction which forces translation mode.
+	FlagTranslate = "-t"
+	// FlagDictionary is a flag after direction which forces dictionary mode.
+	FlagDictionary = "-d"
+	// MaxLookupWords is maximum number of words which are looked up in dictionary
+	// if the mode is not set by a flag, longer texts are translated.
+	MaxLookupWords = 2
+)
+
+// Command is a parsed translation request.
+// Forced is true if the mode is set by a flag.
+type Command struct {
+	Direction string
+	Text      string
+	IsTr      bool
+	Forced    bool
+}
+
+// Parse finds language direction and text in a message like "en-ru some text".
+// It returns false if the message doesn't contain a translation request,
+// the direction must be a whole token at the start of the message as for ParseAll,
+// a direction with several target languages is parsed by ParseAll only.
+// IsTr is true if the text should be translated, false - for a dictionary lookup,
+// the mode can be set by FlagTranslate or FlagDictionary: "en-ru -d New York".
+func Parse(text string) (*Command, bool) {
+	found := LangDirects.FindStringSubmatchIndex(text)
+	if found == nil {
+		return nil, false
+	}
+	direction := text[found[2]:found[3]]
+	if strings.Contains(direction, ",") {
+		return nil, false
+	}
+	return parse(direction, text[found[1]:])
+}
+
+// parse returns a command for the direction and text after it.
+func parse(direction, text string) (*Command, bool) {
+	parsed := strings.Trim(text, " ")
+	cmd := &Command{Direction: strings.Trim(direction, " ")}
+	if flag := strings.SplitN(parsed, " ", 2); len(flag) == 2 && (flag[0] == FlagTranslate || flag[0] == FlagDictionary) {
+		cmd.IsTr, cmd.Forced = flag[0] == FlagTranslate, true
+		parsed = strings.Trim(flag[1], " ")
+	}
+	if parsed == "" || parsed == FlagTranslate || parsed == FlagDictionary {
+		return nil, false
+	}
+	cmd.Text = parsed
+	if !cmd.Forced {
+		cmd.IsTr = IsSentence(parsed)
+	}
+	return cmd, true
+}
+
+// ParseAll finds all commands in a message: every line can start with a command
+// and a direction can have several target languages, "en-ru,de hello" is
+// two commands "en-ru hello" and "en-de hello". Lines without a direction
+// continue the text of previous command, lines before the first command are ignored.
+func ParseAll(text string) []*Command {
+	type line struct {
+		directions []string
+		text       string
+	}
+	var lines []*line
+	for _, value := range strings.Split(text, "\n") {
+		found := LangDirects.FindStringSubmatchIndex(value)
+		if found == nil {
+			if n := len(lines); n > 0 {
+				lines[n-1].text += "\n" + value
+			}
+			continue
+		}
+		targets := strings.Split(value[found[2]:found[3]], ",")
+		source, _ := splitDirection(targets[0])
+		directions := make([]string, len(targets))
+		for i, target := range targets {
+			if i > 0 {
+				target = source + "-" + target
+			}
+			directions[i] = target
+		}
+		lines = append(lines, &line{directions: directions, text: value[found[1]:]})
+	}
+	commands := make([]*Command, 0, len(lines))
+	for _, l := range lines {
+		text := strings.TrimRight(l.text, " \n")
+		for _, direction := range l.directions {
+			if cmd, ok := parse(direction, text); ok {
+				commands = append(commands, cmd)
+			}
+		}
+	}
+	return commands
+}
+
+// IsSentence returns true if the text should be translated, not looked up in dictionary:
+// it has several lines, more than MaxLookupWords words or sentence punctuation.
+// Hyphens and apostrophes are parts of words like "well-known" or "don't".
+func IsSentence(text string) bool {
+	if strings.Contains(text, "\n") || len(strings.Fields(text)) > MaxLookupWords {
+		return true
+	}
+	return strings.IndexFunc(text, func(r rune) bool {
+		switch r {
+		case '-', '\'', '’':
+			return false
+		}
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}) >= 0
+}
+
+// IsDirection checks - "direction" is one of sorted languages directions.
+func IsDirection(languages []string, direction string) bool {
+	if i := sort.SearchStrings(languages, direction); i < len(languages) && languages[i] == direction {
+		return true
+	}
+	return false
+}
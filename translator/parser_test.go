@@ -0,0 +1,95 @@
+package translator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testValues := map[string]*Command{
+		"":                      nil,
+		"text":                  nil,
+		"enru failed":           nil,
+		"en-ru":                 nil,
+		"en-ru   ":              nil,
+		"en-ru word":            {Direction: "en-ru", Text: "word"},
+		"en-ru some words":      {Direction: "en-ru", Text: "some words"},
+		"en-ru some more words": {Direction: "en-ru", Text: "some more words", IsTr: true},
+		"hi, en-ru  word ":      nil,
+		" en-ru  word ":         {Direction: "en-ru", Text: "word"},
+		"we go en-masse":        nil,
+		"en-masse is coming":    nil,
+		"en-ru,de word":         nil,
+		"rus-eng a long text":   {Direction: "rus-eng", Text: "a long text", IsTr: true},
+		"en-ru New York":        {Direction: "en-ru", Text: "New York"},
+		"en-ru don't":           {Direction: "en-ru", Text: "don't"},
+		"en-ru well-known":      {Direction: "en-ru", Text: "well-known"},
+		"en-ru hello, world":    {Direction: "en-ru", Text: "hello, world", IsTr: true},
+		"en-ru hi!":             {Direction: "en-ru", Text: "hi!", IsTr: true},
+		"en-ru -t word":         {Direction: "en-ru", Text: "word", IsTr: true, Forced: true},
+		"en-ru -d look up to":   {Direction: "en-ru", Text: "look up to", Forced: true},
+		"en-ru -d":              nil,
+		"en-ru -x word":         {Direction: "en-ru", Text: "-x word"},
+	}
+	for k, v := range testValues {
+		cmd, ok := Parse(k)
+		if v == nil {
+			if ok {
+				t.Errorf("unexpected command for %q: %+v", k, cmd)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("command not found for %q", k)
+			continue
+		}
+		if *cmd != *v {
+			t.Errorf("wrong command for %q: %+v != %+v", k, cmd, v)
+		}
+	}
+}
+
+func TestIsDirection(t *testing.T) {
+	languages := []string{"en-ru", "ru-en", "ru-pl"}
+	testValues := map[string]bool{
+		"":      false,
+		"en-ru": true,
+		"ru-pl": true,
+		"en-pl": false,
+		"zz-zz": false,
+	}
+	for k, v := range testValues {
+		if r := IsDirection(languages, k); r != v {
+			t.Errorf("wrong result for %q: %v", k, r)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	testValues := map[string]string{
+		"":                        "[]",
+		"no commands":             "[]",
+		"en-ru word":              "[{en-ru word false false}]",
+		"en-ru,de,fr good time":   "[{en-ru good time false false} {en-de good time false false} {en-fr good time false false}]",
+		"en-ru hello\nru-en мир":  "[{en-ru hello false false} {ru-en мир false false}]",
+		"hi\nen-ru one\ntwo\n\n":  "[{en-ru one\ntwo true false}]",
+		"en-ru -t word\nde-en":    "[{en-ru word true true}]",
+		"en-ru,de -d look up to ": "[{en-ru look up to false true} {en-de look up to false true}]",
+		"we go en-masse tonight":  "[]",
+		"I like it-related jobs":  "[]",
+		"en-masse is coming":      "[]",
+		"it-related jobs":         "[]",
+		"hi, en-ru word":          "[]",
+		"  en-ru\tword":           "[{en-ru word false false}]",
+	}
+	for k, v := range testValues {
+		commands := ParseAll(k)
+		values := make([]Command, len(commands))
+		for i, cmd := range commands {
+			values[i] = *cmd
+		}
+		if r := fmt.Sprint(values); r != v {
+			t.Errorf("wrong commands for %q: %q, expected %q", k, r, v)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Package providertest is a contract test harness for translation providers.
+//
+// Run starts a test API server which checks that requests of the provider
+// use its HTTP method, parameters encoding and authentication method,
+// then it calls all translator.Client methods and verifies the results.
+package providertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+const (
+	// TranslationKey is API key of translation requests.
+	TranslationKey = "contract-tkey"
+	// DictionaryKey is API key of dictionary requests.
+	DictionaryKey = "contract-dkey"
+)
+
+// NewProvider returns a provider which API is served by baseURL.
+type NewProvider func(baseURL string) *translator.Provider
+
+// Server is a test API server of a provider.
+type Server struct {
+	*httptest.Server
+	t        testing.TB
+	provider *translator.Provider
+}
+
+// NewServer returns started test API server of the provider.
+func NewServer(t testing.TB, newProvider NewProvider) *Server {
+	s := &Server{t: t}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.provider = newProvider(s.URL)
+	return s
+}
+
+// Provider returns the provider served by this server.
+func (s *Server) Provider() *translator.Provider {
+	return s.provider
+}
+
+// params returns request parameters decoded according to provider's encoding.
+func (s *Server) params(r *http.Request) (url.Values, error) {
+	p := s.provider
+	if r.Method != p.Encoding.Method() {
+		return nil, fmt.Errorf("method %v is not expected for encoding %v", r.Method, p.Encoding)
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch p.Encoding {
+	case translator.EncodingQuery:
+		return r.URL.Query(), nil
+	case translator.EncodingJSON:
+		if mediaType != "application/json" {
+			return nil, fmt.Errorf("content type %q is not JSON", mediaType)
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		data := make(map[string]interface{})
+		if err := json.Unmarshal(b, &data); err != nil {
+			return nil, err
+		}
+		params := make(url.Values, len(data))
+		for name, value := range data {
+			switch v := value.(type) {
+			case string:
+				params.Add(name, v)
+			case []interface{}:
+				for _, item := range v {
+					str, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("parameter %v is not strings array", name)
+					}
+					params.Add(name, str)
+				}
+			default:
+				return nil, fmt.Errorf("parameter %v has wrong type %T", name, value)
+			}
+		}
+		return params, nil
+	default:
+		if mediaType != "application/x-www-form-urlencoded" {
+			return nil, fmt.Errorf("content type %q is not form", mediaType)
+		}
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return r.PostForm, nil
+	}
+}
+
+// key returns API key of the request according to provider's authentication.
+func (s *Server) key(r *http.Request, params url.Values) string {
+	a := s.provider.Auth
+	if a.Header != "" {
+		value := r.Header.Get(a.Header)
+		if !strings.HasPrefix(value, a.Prefix) {
+			return ""
+		}
+		if params.Get(translator.DefaultKeyParam) != "" {
+			s.t.Errorf("API key is sent as parameter and header")
+		}
+		return strings.TrimPrefix(value, a.Prefix)
+	}
+	name := a.Param
+	if name == "" {
+		name = translator.DefaultKeyParam
+	}
+	return params.Get(name)
+}
+
+// reply writes JSON response.
+func reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	params, err := s.params(r)
+	if err != nil {
+		s.t.Errorf("bad request %v: %v", r.URL.Path, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get("User-Agent") == "" {
+		s.t.Errorf("empty user-agent of request %v", r.URL.Path)
+	}
+	key := s.key(r, params)
+	p := s.provider
+	// URLs are compared without query, it is a part of GET request
+	path := r.URL.Path
+	switch path {
+	case urlPath(p.TrLangs), urlPath(p.Translate):
+		if key != TranslationKey {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	case urlPath(p.DictLangs), urlPath(p.Dictionary):
+		if key != DictionaryKey {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+	}
+	switch path {
+	case urlPath(p.TrLangs):
+		reply(w, translator.LangsListTr{
+			Dirs:  []string{"en-ru", "ru-en"},
+			Langs: map[string]string{"en": "English", "ru": "Russian"},
+		})
+	case urlPath(p.DictLangs):
+		reply(w, translator.LangsList{"en-ru", "ru-en", "ru-ru"})
+	case urlPath(p.Translate):
+		texts := params["text"]
+		result := make([]string, len(texts))
+		for i, text := range texts {
+			result[i] = "[" + text + "]"
+		}
+		reply(w, translator.JSONTrResp{Code: 200, Lang: params.Get("lang"), Text: result})
+	case urlPath(p.Dictionary):
+		reply(w, translator.JSONTrDict{Def: []translator.JSONTrDictArticle{{
+			Text: params.Get("text"),
+			Pos:  "noun",
+			Tr:   []translator.JSONTrDictItem{{Text: "[" + params.Get("text") + "]", Pos: "noun"}},
+		}}})
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// urlPath returns path of URL value.
+func urlPath(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	return u.Path
+}
+
+// Run checks that the provider passes API contract.
+func Run(t *testing.T, newProvider NewProvider) {
+	s := NewServer(t, newProvider)
+	defer s.Close()
+	ctx := context.Background()
+	client := translator.NewClient(s.Provider(), TranslationKey, DictionaryKey, time.Second)
+
+	t.Run("TranslationLangs", func(t *testing.T) {
+		langs, err := client.TranslationLangs(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l := langs.Content(); len(l) != 2 || l[0] != "en-ru" {
+			t.Errorf("wrong translation languages: %v", l)
+		}
+	})
+	t.Run("DictionaryLangs", func(t *testing.T) {
+		langs, err := client.DictionaryLangs(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l := langs.Content(); len(l) != 3 || l[0] != "en-ru" {
+			t.Errorf("wrong dictionary languages: %v", l)
+		}
+	})
+	t.Run("Translate", func(t *testing.T) {
+		text := "hello & world = 100%"
+		tr, err := client.Translate(ctx, "en-ru", text)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tr.Lang != "en-ru" || tr.String() != "["+text+"]" {
+			t.Errorf("wrong translation: %v %q", tr.Lang, tr.String())
+		}
+	})
+	t.Run("TranslateBatch", func(t *testing.T) {
+		texts := []string{"one", "two & three", "four"}
+		p := *s.Provider()
+		p.MaxLength = len(texts[1])
+		batch := translator.NewClient(&p, TranslationKey, DictionaryKey, time.Second)
+		result, err := batch.TranslateBatch(ctx, "en-ru", texts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != len(texts) {
+			t.Fatalf("wrong number of translations: %v", result)
+		}
+		for i, text := range texts {
+			if result[i] != "["+text+"]" {
+				t.Errorf("wrong translation %v: %q", i, result[i])
+			}
+		}
+	})
+	t.Run("Lookup", func(t *testing.T) {
+		dict, err := client.Lookup(ctx, "en-ru", "time")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(dict.Def) != 1 || dict.Def[0].Tr[0].Text != "[time]" {
+			t.Errorf("wrong dictionary article: %v", dict)
+		}
+	})
+	t.Run("WrongKey", func(t *testing.T) {
+		wrong := translator.NewClient(s.Provider(), DictionaryKey, TranslationKey, time.Second)
+		if _, err := wrong.Translate(ctx, "en-ru", "hello"); err == nil {
+			t.Error("expected error for wrong translation key")
+		}
+		if _, err := wrong.Lookup(ctx, "en-ru", "time"); err == nil {
+			t.Error("expected error for wrong dictionary key")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package providertest
+
+import (
+	"testing"
+
+	"github.com/z0rr0/transalation-bot/translator"
+)
+
+func TestYandex(t *testing.T) {
+	Run(t, func(baseURL string) *translator.Provider {
+		return translator.NewYandex(baseURL+"/tr.json", baseURL+"/dicservice.json")
+	})
+}
+
+func TestStrategies(t *testing.T) {
+	cases := map[string]struct {
+		encoding translator.Encoding
+		auth     translator.Auth
+	}{
+		"query":       {encoding: translator.EncodingQuery},
+		"json":        {encoding: translator.EncodingJSON},
+		"form_param":  {auth: translator.Auth{Param: "api_key"}},
+		"query_param": {encoding: translator.EncodingQuery, auth: translator.Auth{Param: "api_key"}},
+		"json_header": {
+			encoding: translator.EncodingJSON,
+			auth:     translator.Auth{Header: "Authorization", Prefix: "Api-Key "},
+		},
+		"query_header": {encoding: translator.EncodingQuery, auth: translator.Auth{Header: "X-Api-Key"}},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			Run(t, func(baseURL string) *translator.Provider {
+				p := translator.NewYandex(baseURL+"/tr", baseURL+"/dict")
+				p.Encoding, p.Auth = c.encoding, c.auth
+				return p
+			})
+		})
+	}
+}
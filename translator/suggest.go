@@ -0,0 +1,95 @@
+package translator
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestDistance is maximum edit distance of a suggested direction.
+const maxSuggestDistance = 2
+
+// splitDirection returns source and target languages of the direction.
+func splitDirection(direction string) (string, string) {
+	pair := strings.SplitN(direction, "-", 2)
+	if len(pair) != 2 {
+		return direction, ""
+	}
+	return pair[0], pair[1]
+}
+
+// distance returns Levenshtein edit distance of the strings.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Suggest returns up to n languages directions which are the closest to
+// unknown direction: its reverse direction, then by edit distance,
+// directions with the same source language are preferred.
+// Nothing is suggested if both direction's languages are unknown,
+// so random words like "re-do" don't get suggestions.
+func Suggest(languages []string, direction string, n int) []string {
+	type candidate struct {
+		direction string
+		score     int
+	}
+	source, target := splitDirection(direction)
+	known := make(map[string]bool)
+	for _, d := range languages {
+		from, to := splitDirection(d)
+		known[from], known[to] = true, true
+	}
+	if !known[source] && !known[target] {
+		return nil
+	}
+	reverse := target + "-" + source
+	candidates := make([]candidate, 0, n)
+	for _, d := range languages {
+		if d == direction {
+			continue
+		}
+		dist := distance(direction, d)
+		if d != reverse && dist > maxSuggestDistance {
+			continue
+		}
+		// scores: reverse direction, then distance with a bonus for the same source
+		score := 2 * dist
+		if from, _ := splitDirection(d); from == source {
+			score--
+		}
+		if d == reverse {
+			score = 0
+		}
+		candidates = append(candidates, candidate{direction: d, score: score})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].direction < candidates[j].direction
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	result := make([]string, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.direction
+	}
+	return result
+}
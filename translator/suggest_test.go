@@ -0,0 +1,39 @@
+package translator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	languages := []string{"de-ru", "en-de", "en-ru", "en-uk", "ru-de", "ru-en", "ru-hu", "uk-ru"}
+	testValues := map[string]string{
+		"en-ua":  "[en-uk en-de en-ru]",
+		"ru-eng": "[ru-en]",
+		"hu-ru":  "[ru-hu de-ru en-ru]",
+		"zz-zz":  "[]",
+		"re-do":  "[]",
+		"en-ru":  "[ru-en en-de en-uk]",
+	}
+	for k, v := range testValues {
+		if r := fmt.Sprint(Suggest(languages, k, 3)); r != v {
+			t.Errorf("wrong suggestions for %q: %v, expected %v", k, r, v)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	testValues := map[[2]string]int{
+		{"", ""}:            0,
+		{"en-ru", "en-ru"}:  0,
+		{"en-ua", "en-uk"}:  1,
+		{"ru-eng", "ru-en"}: 1,
+		{"", "en"}:          2,
+		{"en-ru", "ru-en"}:  4,
+	}
+	for k, v := range testValues {
+		if d := distance(k[0], k[1]); d != v {
+			t.Errorf("wrong distance for %q: %v, expected %v", k, d, v)
+		}
+	}
+}
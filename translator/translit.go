@@ -0,0 +1,225 @@
+package translator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Latin is a pseudo language code of Latin script for transliteration directions like "ru-lat".
+const Latin = "lat"
+
+// translitRules are transliteration rules of a language.
+// Initial letters are used at the beginning of a word or after "after" characters.
+// Reverse pairs are Latin sequences sorted from the longest to the shortest.
+type translitRules struct {
+	letters map[rune]string
+	initial map[rune]string
+	after   string
+	reverse []translitPair
+}
+
+// translitPair is a Latin sequence and its Cyrillic letters.
+type translitPair struct {
+	latin    string
+	cyrillic string
+}
+
+var translitLanguages = map[string]*translitRules{
+	// Russian, common chat rules close to passport ones
+	"ru": newTranslitRules(
+		map[rune]string{
+			'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+			'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+			'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+			'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "'", 'э': "e", 'ю': "yu",
+			'я': "ya",
+		},
+		nil,
+		"",
+		[]translitPair{
+			{"shch", "щ"}, {"sch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"},
+			{"sh", "ш"}, {"yo", "ё"}, {"yu", "ю"}, {"ya", "я"}, {"a", "а"}, {"b", "б"},
+			{"v", "в"}, {"g", "г"}, {"d", "д"}, {"e", "е"}, {"z", "з"}, {"i", "и"},
+			{"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"},
+			{"p", "п"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"}, {"f", "ф"},
+			{"h", "х"}, {"c", "ц"}, {"y", "ы"}, {"'", "ь"}, {"x", "кс"}, {"w", "в"},
+			{"q", "к"},
+		},
+	),
+	// Ukrainian, national rules of 2010
+	"uk": newTranslitRules(
+		map[rune]string{
+			'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "e", 'є': "ie",
+			'ж': "zh", 'з': "z", 'и': "y", 'і': "i", 'ї': "i", 'й': "i", 'к': "k", 'л': "l",
+			'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+			'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ь': "", 'ю': "iu",
+			'я': "ia", '\'': "", '’': "",
+		},
+		map[rune]string{'є': "ye", 'ї': "yi", 'й': "y", 'ю': "yu", 'я': "ya"},
+		"",
+		[]translitPair{
+			{"shch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+			{"ye", "є"}, {"yi", "ї"}, {"yu", "ю"}, {"ya", "я"}, {"ie", "є"}, {"iu", "ю"},
+			{"ia", "я"}, {"a", "а"}, {"b", "б"}, {"v", "в"}, {"h", "г"}, {"g", "ґ"},
+			{"d", "д"}, {"e", "е"}, {"z", "з"}, {"y", "и"}, {"i", "і"}, {"j", "й"},
+			{"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"},
+			{"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"}, {"f", "ф"}, {"c", "ц"},
+			{"'", "ь"},
+		},
+	),
+	// Belarusian, national rules of 2007
+	"be": newTranslitRules(
+		map[rune]string{
+			'а': "a", 'б': "b", 'в': "v", 'г': "h", 'ґ': "g", 'д': "d", 'е': "ie", 'ё': "io",
+			'ж': "ž", 'з': "z", 'і': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+			'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ў': "ŭ", 'ф': "f",
+			'х': "ch", 'ц': "c", 'ч': "č", 'ш': "š", 'ы': "y", 'ь': "'", 'э': "e", 'ю': "iu",
+			'я': "ia", '\'': "", '’': "",
+		},
+		map[rune]string{'е': "je", 'ё': "jo", 'ю': "ju", 'я': "ja"},
+		"аеёіоуыэюяьў'’",
+		[]translitPair{
+			{"ch", "х"}, {"je", "е"}, {"jo", "ё"}, {"ju", "ю"}, {"ja", "я"}, {"ie", "е"},
+			{"io", "ё"}, {"iu", "ю"}, {"ia", "я"}, {"a", "а"}, {"b", "б"}, {"v", "в"},
+			{"h", "г"}, {"g", "ґ"}, {"d", "д"}, {"ž", "ж"}, {"z", "з"}, {"i", "і"},
+			{"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"},
+			{"p", "п"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"}, {"ŭ", "ў"},
+			{"f", "ф"}, {"c", "ц"}, {"č", "ч"}, {"š", "ш"}, {"y", "ы"}, {"e", "э"},
+			{"'", "ь"},
+		},
+	),
+}
+
+// newTranslitRules returns transliteration rules with sorted reverse pairs.
+func newTranslitRules(letters, initial map[rune]string, after string, reverse []translitPair) *translitRules {
+	sort.SliceStable(reverse, func(i, j int) bool {
+		return len([]rune(reverse[i].latin)) > len([]rune(reverse[j].latin))
+	})
+	return &translitRules{letters: letters, initial: initial, after: after, reverse: reverse}
+}
+
+// TranslitLanguages returns sorted codes of languages which support transliteration.
+func TranslitLanguages() []string {
+	codes := make([]string, 0, len(translitLanguages))
+	for code := range translitLanguages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// translitLanguage returns transliteration rules of the language.
+func translitLanguage(lang string) (*translitRules, error) {
+	rules, ok := translitLanguages[lang]
+	if !ok {
+		return nil, fmt.Errorf("transliteration is not supported for language %v", lang)
+	}
+	return rules, nil
+}
+
+// withCase returns value in letter case of its source: "Ж" is "Zh", but it's "ZH" inside "ЖУК".
+func withCase(value string, upper, allUpper bool) string {
+	if !upper || value == "" {
+		return value
+	}
+	if allUpper {
+		return strings.ToUpper(value)
+	}
+	runes := []rune(value)
+	return string(unicode.ToUpper(runes[0])) + string(runes[1:])
+}
+
+// isUpperWord returns true if a letter at position i is a part of upper case word.
+func isUpperWord(runes []rune, i, n int) bool {
+	if i+n < len(runes) && unicode.IsLetter(runes[i+n]) {
+		return unicode.IsUpper(runes[i+n])
+	}
+	return i > 0 && unicode.IsUpper(runes[i-1])
+}
+
+// Transliterate converts Cyrillic text of the language to Latin script, other characters are kept.
+func Transliterate(lang, text string) (string, error) {
+	rules, err := translitLanguage(lang)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		value, ok := rules.letters[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if initial, ok := rules.initial[lower]; ok {
+			prev := ' '
+			if i > 0 {
+				prev = unicode.ToLower(runes[i-1])
+			}
+			isWordStart := !unicode.IsLetter(prev) && prev != '\'' && prev != '’'
+			if isWordStart || strings.ContainsRune(rules.after, prev) {
+				value = initial
+			}
+		}
+		b.WriteString(withCase(value, unicode.IsUpper(r), isUpperWord(runes, i, 1)))
+	}
+	return b.String(), nil
+}
+
+// Detransliterate converts Latin script text to Cyrillic by rules of the language, other characters are kept.
+func Detransliterate(lang, text string) (string, error) {
+	rules, err := translitLanguage(lang)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	for i := 0; i < len(runes); {
+		pair, n := rules.match(lower[i:])
+		if n == 0 {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		value := pair.cyrillic
+		if lang == "ru" && pair.latin == "y" && i > 0 && strings.ContainsRune("aeiouy", lower[i-1]) {
+			// "y" after a vowel is "й": "moy" is "мой"
+			value = "й"
+		}
+		b.WriteString(withCase(value, unicode.IsUpper(runes[i]), isUpperWord(runes, i, n)))
+		i += n
+	}
+	return b.String(), nil
+}
+
+// match returns the longest reverse pair at the beginning of lower case text and its length.
+func (rules *translitRules) match(text []rune) (translitPair, int) {
+	for _, pair := range rules.reverse {
+		latin := []rune(pair.latin)
+		if len(latin) <= len(text) && string(text[:len(latin)]) == pair.latin {
+			return pair, len(latin)
+		}
+	}
+	return translitPair{}, 0
+}
+
+// IsLatin returns true if the text has Latin letters and doesn't have Cyrillic ones.
+func IsLatin(text string) bool {
+	hasLatin := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return false
+		case unicode.Is(unicode.Latin, r):
+			hasLatin = true
+		}
+	}
+	return hasLatin
+}
@@ -0,0 +1,69 @@
+package translator
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	testValues := map[[2]string]string{
+		{"ru", "Привет, как дела?"}:     "Privet, kak dela?",
+		{"ru", "ЩУКА и ёж"}:             "SHCHUKA i yozh",
+		{"ru", "Жёлтый подъезд"}:        "Zhyoltyy podezd",
+		{"uk", "Юрій Київ, Знам'янка"}:  "Yurii Kyiv, Znamianka",
+		{"uk", "Ґанок і Єнакієве"}:      "Ganok i Yenakiieve",
+		{"be", "Мінск, Ельск і сям'я"}:  "Minsk, Jel'sk i siamja",
+		{"be", "Чачэрск, Ўзда"}:         "Čačersk, Ŭzda",
+		{"ru", "text без изменений 42"}: "text bez izmeneniy 42",
+	}
+	for k, v := range testValues {
+		result, err := Transliterate(k[0], k[1])
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+	if _, err := Transliterate("en", "text"); err == nil {
+		t.Error("expected error for unsupported language")
+	}
+}
+
+func TestDetransliterate(t *testing.T) {
+	testValues := map[[2]string]string{
+		{"ru", "Privet, kak dela?"}: "Привет, как дела?",
+		{"ru", "moy novyy SHCHIT"}:  "мой новый ЩИТ",
+		{"ru", "chto khorosho"}:     "что хорошо",
+		{"ru", "Zhuk i yozh"}:       "Жук и ёж",
+		{"uk", "Yurii, Kharkiv"}:    "Юріі, Харків",
+		{"be", "Minsk, Čačersk"}:    "Мінск, Чачэрск",
+		{"ru", "привет world"}:      "привет ворлд",
+		{"ru", "12:00"}:             "12:00",
+	}
+	for k, v := range testValues {
+		result, err := Detransliterate(k[0], k[1])
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", k, err)
+		}
+		if result != v {
+			t.Errorf("wrong result for %q: %q, expected %q", k, result, v)
+		}
+	}
+	if _, err := Detransliterate("lat", "text"); err == nil {
+		t.Error("expected error for unsupported language")
+	}
+}
+
+func TestIsLatin(t *testing.T) {
+	testValues := map[string]bool{
+		"privet":     true,
+		"Čačersk 42": true,
+		"привет":     false,
+		"privet мир": false,
+		"42, :)":     false,
+		"":           false,
+	}
+	for k, v := range testValues {
+		if r := IsLatin(k); r != v {
+			t.Errorf("wrong result for %q: %v, expected %v", k, r, v)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package translator
+
+import "sort"
+
+// Translater is an interface to prepare JSON translation response.
+type Translater interface {
+	String() string
+}
+
+// Langer is an interface for translate/dictionary languages collection.
+type Langer interface {
+	Content() []string
+}
+
+// LangsList is a  list of dictionary's languages (from JSON response).
+// It is sorted in ascending order.
+type LangsList []string
+
+// LangsListTr is a list of translation's languages (from JSON response).
+type LangsListTr struct {
+	Dirs  []string          `json:"dirs"`
+	Langs map[string]string `json:"langs"`
+}
+
+// JSONTrDictExample is an internal type of JSONTrDict.
+type JSONTrDictExample struct {
+	Pos  string              `json:"pos"`
+	Text string              `json:"text"`
+	Tr   []map[string]string `json:"tr"`
+}
+
+// JSONTrDictItem is an internal type of JSONTrDict.
+type JSONTrDictItem struct {
+	Text string              `json:"text"`
+	Pos  string              `json:"pos"`
+	Syn  []map[string]string `json:"syn"`
+	Mean []map[string]string `json:"mean"`
+	Ex   []JSONTrDictExample `json:"ex"`
+}
+
+// JSONTrDictArticle is an internal type of JSONTrDict.
+type JSONTrDictArticle struct {
+	Pos  string           `json:"post"`
+	Text string           `json:"text"`
+	Ts   string           `json:"ts"`
+	Gen  string           `json:"gen"`
+	Tr   []JSONTrDictItem `json:"tr"`
+}
+
+// JSONTrDict is a type of a translation dictionary (from JSON response).
+// It supports "Translater" interface.
+type JSONTrDict struct {
+	Head map[string]string   `json:"head"`
+	Def  []JSONTrDictArticle `json:"def"`
+}
+
+// JSONTrResp is a type of a translation (from JSON response).
+// It supports "Translater" interface.
+type JSONTrResp struct {
+	Code float64  `json:"code"`
+	Lang string   `json:"lang"`
+	Text []string `json:"text"`
+}
+
+// Content is LangsList's implementation of Content method.
+func (lg *LangsList) Content() []string {
+	result := []string(*lg)
+	sort.Strings(result)
+	return result
+}
+
+// Content is LangsListTr's implementation of Content method.
+func (lgt *LangsListTr) Content() []string {
+	result := lgt.Dirs
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,214 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultKeyParam is default name of API key request parameter.
+const DefaultKeyParam = "key"
+
+// Encoding is a way to send request parameters to API.
+type Encoding int
+
+// Supported encodings of request parameters.
+const (
+	// EncodingForm sends POST request with application/x-www-form-urlencoded body.
+	EncodingForm Encoding = iota
+	// EncodingQuery sends GET request with URL query parameters.
+	EncodingQuery
+	// EncodingJSON sends POST request with application/json body,
+	// a parameter with several values is sent as an array.
+	EncodingJSON
+)
+
+var encodingNames = map[Encoding]string{
+	EncodingForm:  "form",
+	EncodingQuery: "query",
+	EncodingJSON:  "json",
+}
+
+// String returns encoding name.
+func (e Encoding) String() string {
+	if name, ok := encodingNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// Method returns HTTP method of the encoding.
+func (e Encoding) Method() string {
+	if e == EncodingQuery {
+		return http.MethodGet
+	}
+	return http.MethodPost
+}
+
+// ParseEncoding returns encoding by its name, empty name is EncodingForm.
+func ParseEncoding(name string) (Encoding, error) {
+	if name == "" {
+		return EncodingForm, nil
+	}
+	for e, n := range encodingNames {
+		if n == name {
+			return e, nil
+		}
+	}
+	return EncodingForm, fmt.Errorf("unknown encoding %q", name)
+}
+
+// Auth describes how API key is sent. If Header is set, the key
+// is sent in this HTTP header after Prefix (for example "Api-Key "),
+// otherwise it is a request parameter Param ("key" by default).
+type Auth struct {
+	Param  string
+	Header string
+	Prefix string
+}
+
+// apply adds API key to request headers or parameters.
+func (a Auth) apply(header http.Header, params url.Values, key string) {
+	if a.Header != "" {
+		header.Set(a.Header, a.Prefix+key)
+		return
+	}
+	name := a.Param
+	if name == "" {
+		name = DefaultKeyParam
+	}
+	params.Set(name, key)
+}
+
+// Upstream is HTTP client of translation API
+// with request method, parameters encoding and authentication strategies.
+type Upstream struct {
+	HTTPClient *http.Client
+	Encoding   Encoding
+	Auth       Auth
+	Timeout    time.Duration
+	UserAgent  string
+}
+
+// Upstream returns API client of the provider with client's HTTP settings.
+func (c *Client) Upstream() *Upstream {
+	return &Upstream{
+		HTTPClient: c.HTTPClient,
+		Encoding:   c.Provider.Encoding,
+		Auth:       c.Provider.Auth,
+		Timeout:    c.Timeout,
+		UserAgent:  c.UserAgent,
+	}
+}
+
+// newRequest returns HTTP request with encoded parameters and API key.
+func (u *Upstream) newRequest(urlValue string, params url.Values, key string) (*http.Request, error) {
+	var body io.Reader
+	header := make(http.Header)
+	// params are copied, so the key is not added to caller's values
+	values := make(url.Values, len(params)+1)
+	for name, v := range params {
+		values[name] = v
+	}
+	u.Auth.apply(header, values, key)
+	switch u.Encoding {
+	case EncodingQuery:
+		// parameters are added to URL below
+	case EncodingJSON:
+		data := make(map[string]interface{}, len(values))
+		for name, v := range values {
+			if len(v) == 1 {
+				data[name] = v[0]
+			} else {
+				data[name] = v
+			}
+		}
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+		header.Set("Content-Type", "application/json")
+	default:
+		body = strings.NewReader(values.Encode())
+		header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req, err := http.NewRequest(u.Encoding.Method(), urlValue, body)
+	if err != nil {
+		return nil, err
+	}
+	if u.Encoding == EncodingQuery {
+		query := req.URL.Query()
+		for name, v := range values {
+			query[name] = append(query[name], v...)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	userAgent := u.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	header.Set("User-Agent", userAgent)
+	for name, v := range header {
+		req.Header[name] = v
+	}
+	return req, nil
+}
+
+// request sends API request and returns []byte response.
+func (u *Upstream) request(ctx context.Context, urlValue string, params url.Values, key string) ([]byte, error) {
+	var resp *http.Response
+	req, err := u.newRequest(urlValue, params, key)
+	if err != nil {
+		return nil, err
+	}
+	timeout := u.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	httpClient := u.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	ec := make(chan error)
+	go func() {
+		resp, err = httpClient.Do(req)
+		ec <- err
+		close(ec)
+	}()
+	select {
+	case <-ctx.Done():
+		<-ec // wait error "context deadline exceeded"
+		return nil, fmt.Errorf("timed out (%v)", timeout)
+	case err := <-ec:
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("wrong response code=%v", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Call sends API request with params authenticated by key
+// and decodes JSON response to result.
+func (u *Upstream) Call(ctx context.Context, urlValue string, params url.Values, key string, result interface{}) error {
+	body, err := u.request(ctx, urlValue, params, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, result)
+}